@@ -0,0 +1,165 @@
+// Copyright © 2018-2021 Wei Shen <shenwei356@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package kmers
+
+import (
+	"bytes"
+)
+
+// BisulfiteMode is the base conversion applied in bisulfite-aware encoding.
+type BisulfiteMode int
+
+const (
+	// NoConversion means no base is converted.
+	NoConversion BisulfiteMode = iota
+	// BisulfiteCT converts C to T, for sequences from the original strand.
+	BisulfiteCT
+	// BisulfiteGA converts G to A, for sequences from the complementary strand,
+	// i.e., the reverse complement of a C->T converted sequence.
+	BisulfiteGA
+)
+
+// bisulfiteMaps maps 2-bit codes to converted ones, 4 is for illegal bases.
+var bisulfiteMaps = [3][5]uint64{
+	{0, 1, 2, 3, 4}, // NoConversion
+	{0, 3, 2, 3, 4}, // BisulfiteCT
+	{0, 1, 0, 3, 4}, // BisulfiteGA
+}
+
+// String returns the name of the mode.
+func (mode BisulfiteMode) String() string {
+	switch mode {
+	case BisulfiteCT:
+		return "C->T"
+	case BisulfiteGA:
+		return "G->A"
+	default:
+		return "none"
+	}
+}
+
+// RevComp returns the conversion mode of the reverse complement sequence.
+func (mode BisulfiteMode) RevComp() BisulfiteMode {
+	switch mode {
+	case BisulfiteCT:
+		return BisulfiteGA
+	case BisulfiteGA:
+		return BisulfiteCT
+	default:
+		return mode
+	}
+}
+
+// EncodeBisulfite converts byte slice to bits, with bases converted on the fly.
+// Degenerate bases are handled in the same way as Encode before the conversion.
+func EncodeBisulfite(kmer []byte, mode BisulfiteMode) (code uint64, err error) {
+	if len(kmer) == 0 || len(kmer) > 32 {
+		return 0, ErrKOverflow
+	}
+
+	m := &bisulfiteMaps[mode]
+	var v uint64
+	for _, b := range kmer {
+		code <<= 2
+		v = m[base2bit[b]]
+		if v == 4 {
+			return code, ErrIllegalBase
+		}
+		code |= v
+	}
+	return code, nil
+}
+
+// MustEncodeBisulfiteFromFormerKmer encodes from former the k-mer with bases converted,
+// assuming the k-mer and leftKmer are both OK.
+func MustEncodeBisulfiteFromFormerKmer(kmer []byte, leftKmer []byte, leftCode uint64, mode BisulfiteMode) (uint64, error) {
+	v := bisulfiteMaps[mode][base2bit[kmer[len(kmer)-1]]]
+	if v == 4 {
+		return leftCode, ErrIllegalBase
+	}
+	return (leftCode&((1<<(uint(len(kmer)-1)<<1))-1))<<2 | v, nil
+}
+
+// EncodeBisulfiteFromFormerKmer encodes from the former k-mer with bases converted.
+func EncodeBisulfiteFromFormerKmer(kmer []byte, leftKmer []byte, leftCode uint64, mode BisulfiteMode) (uint64, error) {
+	if len(kmer) == 0 {
+		return 0, ErrKOverflow
+	}
+	if len(kmer) != len(leftKmer) {
+		return 0, ErrKMismatch
+	}
+	if !bytes.Equal(kmer[0:len(kmer)-1], leftKmer[1:]) {
+		return 0, ErrNotConsecutiveKmers
+	}
+	return MustEncodeBisulfiteFromFormerKmer(kmer, leftKmer, leftCode, mode)
+}
+
+// MustEncodeBisulfiteFromLatterKmer encodes from the latter k-mer with bases converted,
+// assuming the k-mer and rightKmer are both OK.
+func MustEncodeBisulfiteFromLatterKmer(kmer []byte, rightKmer []byte, rightCode uint64, mode BisulfiteMode) (uint64, error) {
+	v := bisulfiteMaps[mode][base2bit[kmer[0]]]
+	if v == 4 {
+		return rightCode, ErrIllegalBase
+	}
+	return v<<(uint(len(kmer)-1)<<1) | rightCode>>2, nil
+}
+
+// EncodeBisulfiteFromLatterKmer encodes from the latter k-mer with bases converted.
+func EncodeBisulfiteFromLatterKmer(kmer []byte, rightKmer []byte, rightCode uint64, mode BisulfiteMode) (uint64, error) {
+	if len(kmer) == 0 {
+		return 0, ErrKOverflow
+	}
+	if len(kmer) != len(rightKmer) {
+		return 0, ErrKMismatch
+	}
+	if !bytes.Equal(rightKmer[0:len(kmer)-1], kmer[1:len(rightKmer)]) {
+		return 0, ErrNotConsecutiveKmers
+	}
+	return MustEncodeBisulfiteFromLatterKmer(kmer, rightKmer, rightCode, mode)
+}
+
+// ConvertBisulfite applies the conversion to the code of an unconverted k-mer.
+func ConvertBisulfite(code uint64, k int, mode BisulfiteMode) uint64 {
+	if k <= 0 || k > 32 {
+		panic(ErrKOverflow)
+	}
+	lows := 0x5555555555555555 & uint64((1<<uint(k<<1))-1) // lower bit of every base
+	switch mode {
+	case BisulfiteCT: // 01 -> 11
+		return code | (code&lows)<<1
+	case BisulfiteGA: // 10 -> 00
+		return code &^ ((^code & lows) << 1)
+	default:
+		return code
+	}
+}
+
+// CanonicalBisulfite returns the canonical code of a converted k-mer,
+// and the conversion mode of the canonical one.
+// As the reverse complement of a C->T converted k-mer is a G->A converted one,
+// a k-mer from either strand has the same canonical code and mode.
+func CanonicalBisulfite(code uint64, k int, mode BisulfiteMode) (uint64, BisulfiteMode) {
+	rc := RevComp(code, k)
+	if rc < code {
+		return rc, mode.RevComp()
+	}
+	return code, mode
+}
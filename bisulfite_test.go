@@ -0,0 +1,74 @@
+// Copyright © 2018-2021 Wei Shen <shenwei356@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package kmers
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestBisulfite(t *testing.T) {
+	seq := bytes.ToUpper(benchMer)
+	k := 7
+	for _, mode := range []BisulfiteMode{NoConversion, BisulfiteCT, BisulfiteGA} {
+		var converted []byte
+		switch mode {
+		case BisulfiteCT:
+			converted = bytes.Replace(seq, []byte("C"), []byte("T"), -1)
+		case BisulfiteGA:
+			converted = bytes.Replace(seq, []byte("G"), []byte("A"), -1)
+		default:
+			converted = seq
+		}
+
+		var pCode uint64
+		for i := 0; i+k <= len(seq); i++ {
+			kmer := seq[i : i+k]
+			code, err := EncodeBisulfite(kmer, mode)
+			code0, _ := Encode(converted[i : i+k])
+			if err != nil || code != code0 {
+				t.Errorf("%s: EncodeBisulfite error for %s", mode, kmer)
+			}
+			if c := ConvertBisulfite(mustEncode(kmer), k, mode); c != code0 {
+				t.Errorf("%s: ConvertBisulfite error for %s", mode, kmer)
+			}
+			if i > 0 {
+				c, err := EncodeBisulfiteFromFormerKmer(kmer, seq[i-1:i-1+k], pCode, mode)
+				if err != nil || c != code0 {
+					t.Errorf("%s: EncodeBisulfiteFromFormerKmer error for %s", mode, kmer)
+				}
+				c, err = EncodeBisulfiteFromLatterKmer(seq[i-1:i-1+k], kmer, code, mode)
+				if err != nil || c != pCode {
+					t.Errorf("%s: EncodeBisulfiteFromLatterKmer error for %s", mode, seq[i-1:i-1+k])
+				}
+			}
+			pCode = code
+
+			// the reverse complement strand
+			rcCode, _ := EncodeBisulfite(revCompBytes(converted[i:i+k]), mode.RevComp())
+			c1, m1 := CanonicalBisulfite(code, k, mode)
+			c2, m2 := CanonicalBisulfite(rcCode, k, mode.RevComp())
+			if c1 != c2 || m1 != m2 {
+				t.Errorf("%s: CanonicalBisulfite error for %s", mode, kmer)
+			}
+		}
+	}
+}
@@ -0,0 +1,282 @@
+// Copyright © 2018-2021 Wei Shen <shenwei356@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package kmers
+
+import (
+	"errors"
+	"math"
+	"sort"
+)
+
+// ErrInvalidSketchSize means the sketch size is not positive.
+var ErrInvalidSketchSize = errors.New("kmers: invalid sketch size")
+
+// BottomKMinHash is a bottom-k MinHash sketch of canonical k-mers,
+// which keeps the smallest distinct hash values with a bounded max-heap.
+type BottomKMinHash struct {
+	k    int
+	size int
+
+	heap  []uint64            // max-heap of kept hashes
+	set   map[uint64]struct{} // for removing duplicates
+	count uint64              // number of added k-mers
+}
+
+// NewBottomKMinHash creates a bottom-k MinHash sketch keeping at most size hashes.
+func NewBottomKMinHash(k int, size int) (*BottomKMinHash, error) {
+	if k <= 0 || k > 32 {
+		return nil, ErrKOverflow
+	}
+	if size <= 0 {
+		return nil, ErrInvalidSketchSize
+	}
+	return &BottomKMinHash{
+		k:    k,
+		size: size,
+		heap: make([]uint64, 0, size),
+		set:  make(map[uint64]struct{}, size),
+	}, nil
+}
+
+// K returns the k-mer size.
+func (s *BottomKMinHash) K() int {
+	return s.k
+}
+
+// Size returns the maximum number of hashes.
+func (s *BottomKMinHash) Size() int {
+	return s.size
+}
+
+// Len returns the number of hashes.
+func (s *BottomKMinHash) Len() int {
+	return len(s.heap)
+}
+
+// Count returns the number of added k-mers, which is used as the genome size in PValue.
+func (s *BottomKMinHash) Count() uint64 {
+	return s.count
+}
+
+// Add adds a canonical k-mer code, e.g., from Canonical() or CanonicalKmerIterator.
+func (s *BottomKMinHash) Add(code uint64) {
+	s.count++
+	s.addHash(hash64(code, 1<<64-1))
+}
+
+func (s *BottomKMinHash) addHash(h uint64) {
+	if len(s.heap) == s.size && h >= s.heap[0] {
+		return
+	}
+	if _, ok := s.set[h]; ok {
+		return
+	}
+	s.set[h] = struct{}{}
+
+	if len(s.heap) < s.size { // push
+		s.heap = append(s.heap, h)
+		s.up(len(s.heap) - 1)
+		return
+	}
+
+	// replace the biggest one
+	delete(s.set, s.heap[0])
+	s.heap[0] = h
+	s.down(0)
+}
+
+func (s *BottomKMinHash) up(i int) {
+	h := s.heap
+	var p int
+	for i > 0 {
+		p = (i - 1) >> 1
+		if h[p] >= h[i] {
+			break
+		}
+		h[p], h[i] = h[i], h[p]
+		i = p
+	}
+}
+
+func (s *BottomKMinHash) down(i int) {
+	h := s.heap
+	n := len(h)
+	var c int
+	for {
+		c = i<<1 + 1
+		if c >= n {
+			break
+		}
+		if c+1 < n && h[c+1] > h[c] {
+			c++
+		}
+		if h[i] >= h[c] {
+			break
+		}
+		h[i], h[c] = h[c], h[i]
+		i = c
+	}
+}
+
+// AddSeq adds all canonical k-mers of a sequence.
+func (s *BottomKMinHash) AddSeq(seq []byte) error {
+	iter, err := NewCanonicalKmerIterator(seq, s.k)
+	if err != nil {
+		return err
+	}
+	var code uint64
+	var ok bool
+	for {
+		code, _, _, ok = iter.Next()
+		if !ok {
+			break
+		}
+		s.Add(code)
+	}
+	return iter.Err()
+}
+
+// Hashes returns sorted hash values.
+func (s *BottomKMinHash) Hashes() []uint64 {
+	hashes := make(CodeSlice, len(s.heap))
+	copy(hashes, s.heap)
+	sort.Sort(hashes)
+	return hashes
+}
+
+// Kmers returns k-mer codes recovered from sorted hash values.
+func (s *BottomKMinHash) Kmers() []uint64 {
+	codes := s.Hashes()
+	for i, h := range codes {
+		codes[i] = unhash64(h, 1<<64-1)
+	}
+	return codes
+}
+
+// Merge merges another sketch, e.g., computed from another shard of the
+// same genome, into this one.
+func (s *BottomKMinHash) Merge(s2 *BottomKMinHash) error {
+	if s.k != s2.k {
+		return ErrKMismatch
+	}
+	for _, h := range s2.heap {
+		s.addHash(h)
+	}
+	s.count += s2.count
+	return nil
+}
+
+// Jaccard estimates the Jaccard index from the bottom min(size1, size2)
+// hashes of the union of two sketches, and also returns the number of
+// shared hashes and the number of hashes compared.
+func (s *BottomKMinHash) Jaccard(s2 *BottomKMinHash) (j float64, shared int, total int, err error) {
+	if s.k != s2.k {
+		return 0, 0, 0, ErrKMismatch
+	}
+	size := s.size
+	if s2.size < size {
+		size = s2.size
+	}
+
+	a, b := s.Hashes(), s2.Hashes()
+	var i, n int
+	for total < size && i < len(a) && n < len(b) {
+		if a[i] == b[n] {
+			shared++
+			i++
+			n++
+		} else if a[i] < b[n] {
+			i++
+		} else {
+			n++
+		}
+		total++
+	}
+	for total < size && i < len(a) {
+		i++
+		total++
+	}
+	for total < size && n < len(b) {
+		n++
+		total++
+	}
+
+	if total == 0 {
+		return 0, 0, 0, nil
+	}
+	return float64(shared) / float64(total), shared, total, nil
+}
+
+// Distance computes the Mash distance and its p-value between two sketches.
+func (s *BottomKMinHash) Distance(s2 *BottomKMinHash) (dist float64, pvalue float64, err error) {
+	j, shared, total, err := s.Jaccard(s2)
+	if err != nil {
+		return 0, 0, err
+	}
+	return MashDistance(j, s.k), PValue(shared, total, s.k, s.count, s2.count), nil
+}
+
+// MashDistance computes the Mash distance D = -1/k * ln(2j/(1+j))
+// from a Jaccard index j. It returns 1 for j = 0.
+func MashDistance(j float64, k int) float64 {
+	if j <= 0 {
+		return 1
+	}
+	if j >= 1 {
+		return 0
+	}
+	return -math.Log(2*j/(1+j)) / float64(k)
+}
+
+// PValue computes the probability of observing at least shared hashes
+// among total ones by chance, for two random genomes with sizeX and sizeY k-mers.
+// Please see the Mash paper (Ondov et al. 2016) for details.
+func PValue(shared int, total int, k int, sizeX uint64, sizeY uint64) float64 {
+	if shared <= 0 {
+		return 1
+	}
+
+	// probability of a random k-mer appearing in a genome
+	lq := math.Log1p(-math.Pow(4, -float64(k)))
+	pX := -math.Expm1(float64(sizeX) * lq)
+	pY := -math.Expm1(float64(sizeY) * lq)
+	r := pX * pY / (pX + pY - pX*pY)
+	if r <= 0 {
+		return 0
+	}
+	if r >= 1 {
+		return 1
+	}
+
+	// binomial tail P(X >= shared)
+	lr, l1r := math.Log(r), math.Log1p(-r)
+	lgt, _ := math.Lgamma(float64(total + 1))
+	var p, lgi, lgti float64
+	for i := shared; i <= total; i++ {
+		lgi, _ = math.Lgamma(float64(i + 1))
+		lgti, _ = math.Lgamma(float64(total - i + 1))
+		p += math.Exp(lgt - lgi - lgti + float64(i)*lr + float64(total-i)*l1r)
+	}
+	if p > 1 {
+		p = 1
+	}
+	return p
+}
@@ -0,0 +1,101 @@
+// Copyright © 2018-2021 Wei Shen <shenwei356@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package kmers
+
+import (
+	"math"
+	"testing"
+)
+
+func TestBottomKMinHash(t *testing.T) {
+	k, size := 21, 1000
+	shared := randomSeq(50000)
+	seq1 := append(append([]byte{}, shared...), randomSeq(50000)...)
+	seq2 := append(revCompBytes(shared), randomSeq(150000)...)
+
+	s1, _ := NewBottomKMinHash(k, size)
+	s2, _ := NewBottomKMinHash(k, size)
+	if err := s1.AddSeq(seq1); err != nil {
+		t.Fatalf("AddSeq error: %s", err)
+	}
+	s2.AddSeq(seq2)
+	if s1.Len() != size {
+		t.Errorf("BottomKMinHash error: %d hashes kept", s1.Len())
+	}
+	hashes := s1.Hashes()
+	for i := 1; i < len(hashes); i++ {
+		if hashes[i-1] >= hashes[i] {
+			t.Fatalf("Hashes error: not sorted or duplicated")
+		}
+	}
+
+	// the bottom-k hashes of all k-mers
+	f, _ := NewFracMinHash(k, 1, false)
+	f.AddSeq(seq1)
+	if all := f.Hashes(); all[size-1] != hashes[size-1] {
+		t.Errorf("BottomKMinHash error: the biggest hash should be %d, returned %d", all[size-1], hashes[size-1])
+	}
+
+	j, _, total, err := s1.Jaccard(s2)
+	if err != nil || total != size || math.Abs(j-0.2) > 0.05 {
+		t.Errorf("Jaccard error: expected ~%f, returned %f", 0.2, j)
+	}
+	d, p, _ := s1.Distance(s2)
+	if math.Abs(d-MashDistance(0.2, k)) > 0.01 || p > 1e-10 {
+		t.Errorf("Distance error: distance %f, p-value %e", d, p)
+	}
+
+	// unrelated sequences
+	s3, _ := NewBottomKMinHash(k, size)
+	s3.AddSeq(randomSeq(100000))
+	if d, p, _ = s1.Distance(s3); d != 1 || p != 1 {
+		t.Errorf("Distance error: distance %f, p-value %e", d, p)
+	}
+
+	// merging shards
+	s4, _ := NewBottomKMinHash(k, size)
+	s5, _ := NewBottomKMinHash(k, size)
+	s4.AddSeq(seq1[:60000])
+	s5.AddSeq(seq1[60000-k+1:])
+	if err = s4.Merge(s5); err != nil {
+		t.Errorf("Merge error: %s", err)
+	}
+	if j, _, _, _ = s1.Jaccard(s4); j != 1 || s4.Count() != s1.Count() {
+		t.Errorf("Merge error: Jaccard %f, count %d", j, s4.Count())
+	}
+}
+
+func TestMashDistance(t *testing.T) {
+	if d := MashDistance(1, 21); d != 0 {
+		t.Errorf("MashDistance error: %f", d)
+	}
+	if d := MashDistance(0.5, 21); math.Abs(d-0.019308) > 1e-6 {
+		t.Errorf("MashDistance error: %f", d)
+	}
+	if p := PValue(0, 1000, 21, 1e6, 1e6); p != 1 {
+		t.Errorf("PValue error: %e", p)
+	}
+	p1 := PValue(1, 1000, 16, 5e6, 5e6)
+	p2 := PValue(10, 1000, 16, 5e6, 5e6)
+	if !(p1 > p2 && p2 > 0) {
+		t.Errorf("PValue error: %e, %e", p1, p2)
+	}
+}
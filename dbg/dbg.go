@@ -0,0 +1,319 @@
+// Copyright © 2018-2021 Wei Shen <shenwei356@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package dbg provides a node-centric de Bruijn graph of canonical k-mers.
+package dbg
+
+import (
+	"errors"
+	"math"
+	"math/bits"
+	"sort"
+
+	"github.com/shenwei356/kmers"
+)
+
+// ErrUnsortedCodes means codes are not sorted, unique and canonical.
+var ErrUnsortedCodes = errors.New("dbg: codes should be sorted, unique and canonical")
+
+// Graph is a node-centric de Bruijn graph, where nodes are canonical k-mers
+// stored in a sorted slice, and edges are implied by (k-1)-overlaps between k-mers,
+// so nodes are looked up with binary search instead of a hash table.
+type Graph struct {
+	k      int
+	nodes  []uint64 // sorted canonical codes
+	counts []uint32 // abundances of nodes, nil for not provided
+	edges  []uint8  // edges of nodes, see below
+}
+
+// Edges of a node on the forward strand are stored in a byte, where the lower
+// 4 bits are for successors by the appended base A, C, G and T, and the higher
+// 4 bits are for predecessors by the prepended base A, C, G and T.
+
+// Node is a node on one strand, i.e., a canonical k-mer and its orientation.
+type Node struct {
+	Code uint64 // canonical code
+	RC   bool   // whether it's on the reverse complement strand
+}
+
+// Kmer returns the code of the k-mer on the strand of the node.
+func (n Node) Kmer(k int) uint64 {
+	if n.RC {
+		return kmers.MustRevComp(n.Code, k)
+	}
+	return n.Code
+}
+
+// Flip returns the node on the other strand.
+func (n Node) Flip() Node {
+	return Node{n.Code, !n.RC}
+}
+
+// New builds a graph from k-mer codes, which will be converted to canonical ones,
+// sorted and deduplicated. The input slice is not modified.
+func New(codes []uint64, k int) (*Graph, error) {
+	if k <= 0 || k > 32 {
+		return nil, kmers.ErrKOverflow
+	}
+
+	nodes := make(kmers.CodeSlice, len(codes))
+	for i, code := range codes {
+		nodes[i] = kmers.MustCanonical(code, k)
+	}
+	sort.Sort(nodes)
+
+	var j int
+	for i, code := range nodes {
+		if i > 0 && code == nodes[j-1] {
+			continue
+		}
+		nodes[j] = code
+		j++
+	}
+	return newGraph(nodes[:j], nil, k), nil
+}
+
+// NewFromSorted builds a graph from sorted, unique and canonical k-mer codes,
+// e.g., a sorted CodeSlice. The slice is used directly without copying.
+func NewFromSorted(codes kmers.CodeSlice, k int) (*Graph, error) {
+	if k <= 0 || k > 32 {
+		return nil, kmers.ErrKOverflow
+	}
+	for i, code := range codes {
+		if (i > 0 && code <= codes[i-1]) || code != kmers.MustCanonical(code, k) {
+			return nil, ErrUnsortedCodes
+		}
+	}
+	return newGraph(codes, nil, k), nil
+}
+
+// NewFromCounts builds a graph from a k-mer counter,
+// where k-mers will be converted to canonical ones, and abundances are summed up,
+// saturating at math.MaxUint32.
+func NewFromCounts(counts map[uint64]uint32, k int) (*Graph, error) {
+	if k <= 0 || k > 32 {
+		return nil, kmers.ErrKOverflow
+	}
+
+	m := make(map[uint64]uint32, len(counts))
+	var c uint64
+	for code, n := range counts {
+		c = kmers.MustCanonical(code, k)
+		if s := m[c]; n > math.MaxUint32-s {
+			m[c] = math.MaxUint32
+		} else {
+			m[c] = s + n
+		}
+	}
+	nodes := make(kmers.CodeSlice, 0, len(m))
+	for code := range m {
+		nodes = append(nodes, code)
+	}
+	sort.Sort(nodes)
+
+	abundances := make([]uint32, len(nodes))
+	for i, code := range nodes {
+		abundances[i] = m[code]
+	}
+	return newGraph(nodes, abundances, k), nil
+}
+
+func newGraph(nodes []uint64, counts []uint32, k int) *Graph {
+	g := &Graph{k: k, nodes: nodes, counts: counts, edges: make([]uint8, len(nodes))}
+
+	var e uint8
+	var b uint
+	for i, code := range nodes {
+		e = 0
+		succs, _ := kmers.MustCanonicalSuccessors(code, k)
+		preds, _ := kmers.MustCanonicalPredecessors(code, k)
+		for b = 0; b < 4; b++ {
+			if g.Index(succs[b]) >= 0 {
+				e |= 1 << b
+			}
+			if g.Index(preds[b]) >= 0 {
+				e |= 1 << (b + 4)
+			}
+		}
+		g.edges[i] = e
+	}
+	return g
+}
+
+// K returns the k-mer size.
+func (g *Graph) K() int {
+	return g.k
+}
+
+// Len returns the number of nodes.
+func (g *Graph) Len() int {
+	return len(g.nodes)
+}
+
+// Nodes returns sorted canonical codes of all nodes.
+func (g *Graph) Nodes() []uint64 {
+	return g.nodes
+}
+
+// Index returns the index of a canonical code in Nodes(), -1 for absent ones.
+func (g *Graph) Index(code uint64) int {
+	i := sort.Search(len(g.nodes), func(i int) bool { return g.nodes[i] >= code })
+	if i < len(g.nodes) && g.nodes[i] == code {
+		return i
+	}
+	return -1
+}
+
+// Node returns the node of a k-mer in either orientation, and whether it exists.
+func (g *Graph) Node(code uint64) (Node, bool) {
+	rc := kmers.MustRevComp(code, g.k)
+	n := Node{code, false}
+	if rc < code {
+		n = Node{rc, true}
+	}
+	return n, g.Index(n.Code) >= 0
+}
+
+// Count returns the abundance of a node, 0 for absent nodes,
+// and 1 for all nodes if the graph is not built from a k-mer counter.
+func (g *Graph) Count(n Node) uint32 {
+	i := g.Index(n.Code)
+	if i < 0 {
+		return 0
+	}
+	if g.counts == nil {
+		return 1
+	}
+	return g.counts[i]
+}
+
+// reverse4 reverses the order of the lower 4 bits.
+var reverse4 = [16]uint8{0, 8, 4, 12, 2, 10, 6, 14, 1, 9, 5, 13, 3, 11, 7, 15}
+
+// OutEdges returns a 4-bit mask of successors of a node on its strand,
+// by the appended base A, C, G and T. 0 is returned for absent nodes.
+func (g *Graph) OutEdges(n Node) uint8 {
+	i := g.Index(n.Code)
+	if i < 0 {
+		return 0
+	}
+	if n.RC {
+		// successors of the reverse strand by base b are the reverse complements
+		// of predecessors of the forward strand by the complement base 3-b.
+		return reverse4[g.edges[i]>>4]
+	}
+	return g.edges[i] & 15
+}
+
+// InEdges returns a 4-bit mask of predecessors of a node on its strand,
+// by the prepended base A, C, G and T. 0 is returned for absent nodes.
+func (g *Graph) InEdges(n Node) uint8 {
+	i := g.Index(n.Code)
+	if i < 0 {
+		return 0
+	}
+	if n.RC {
+		return reverse4[g.edges[i]&15]
+	}
+	return g.edges[i] >> 4
+}
+
+// OutDegree returns the number of successors of a node on its strand.
+func (g *Graph) OutDegree(n Node) int {
+	return bits.OnesCount8(g.OutEdges(n))
+}
+
+// InDegree returns the number of predecessors of a node on its strand.
+func (g *Graph) InDegree(n Node) int {
+	return bits.OnesCount8(g.InEdges(n))
+}
+
+// Successors returns successors of a node on its strand.
+func (g *Graph) Successors(n Node) []Node {
+	e := g.OutEdges(n)
+	if e == 0 {
+		return nil
+	}
+	codes, rcs := kmers.MustCanonicalSuccessors(n.Kmer(g.k), g.k)
+	nodes := make([]Node, 0, 4)
+	for b := uint(0); b < 4; b++ {
+		if e>>b&1 == 1 {
+			nodes = append(nodes, Node{codes[b], rcs[b]})
+		}
+	}
+	return nodes
+}
+
+// Predecessors returns predecessors of a node on its strand.
+func (g *Graph) Predecessors(n Node) []Node {
+	e := g.InEdges(n)
+	if e == 0 {
+		return nil
+	}
+	codes, rcs := kmers.MustCanonicalPredecessors(n.Kmer(g.k), g.k)
+	nodes := make([]Node, 0, 4)
+	for b := uint(0); b < 4; b++ {
+		if e>>b&1 == 1 {
+			nodes = append(nodes, Node{codes[b], rcs[b]})
+		}
+	}
+	return nodes
+}
+
+// Traverse visits nodes reachable from a node in breadth-first order,
+// following out edges and strands. Each node on each strand is visited only once,
+// along with its depth. The traversal stops when fn returns false.
+func (g *Graph) Traverse(start Node, fn func(n Node, depth int) bool) {
+	i := g.Index(start.Code)
+	if i < 0 {
+		return
+	}
+
+	visited := make([]uint8, len(g.nodes)) // 1 for the forward strand, 2 for the reverse one
+	visited[i] = strandBit(start)
+	type item struct {
+		node  Node
+		depth int
+	}
+	queue := []item{{start, 0}}
+	var it item
+	for len(queue) > 0 {
+		it, queue = queue[0], queue[1:]
+		if !fn(it.node, it.depth) {
+			return
+		}
+		for _, n := range g.Successors(it.node) {
+			i = g.Index(n.Code)
+			if visited[i]&strandBit(n) > 0 {
+				continue
+			}
+			visited[i] |= strandBit(n)
+			queue = append(queue, item{n, it.depth + 1})
+		}
+	}
+}
+
+// strandBit returns 1 for nodes on the forward strand, and 2 for the reverse ones.
+func strandBit(n Node) uint8 {
+	if n.RC {
+		return 2
+	}
+	return 1
+}
@@ -0,0 +1,156 @@
+// Copyright © 2018-2021 Wei Shen <shenwei356@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dbg
+
+import (
+	"math"
+	"testing"
+
+	"github.com/shenwei356/kmers"
+)
+
+func kmersOf(seq string, k int) []uint64 {
+	iter, _ := kmers.NewKmerIterator([]byte(seq), k)
+	var codes []uint64
+	for {
+		code, _, ok := iter.Next()
+		if !ok {
+			break
+		}
+		codes = append(codes, code)
+	}
+	return codes
+}
+
+func TestGraph(t *testing.T) {
+	k := 5
+	seq := "ACGTTGCAAGGCTTAGCCATGGCATTACGATCGGATCCA"
+	codes := kmersOf(seq, k)
+	g, err := New(codes, k)
+	if err != nil {
+		t.Fatalf("New error: %s", err)
+	}
+
+	set := make(map[uint64]bool)
+	for _, code := range codes {
+		set[kmers.Canonical(code, k)] = true
+	}
+	if g.Len() != len(set) {
+		t.Errorf("Len error: expected %d, returned %d", len(set), g.Len())
+	}
+
+	for _, code := range g.Nodes() {
+		for _, rc := range []bool{false, true} {
+			n := Node{code, rc}
+			kmer := n.Kmer(k)
+
+			var out, in int
+			for b, s := range kmers.Successors(kmer, k) {
+				if set[kmers.Canonical(s, k)] {
+					out++
+					if g.OutEdges(n)>>uint(b)&1 != 1 {
+						t.Errorf("OutEdges error: %s", kmers.Decode(kmer, k))
+					}
+				}
+			}
+			for b, p := range kmers.Predecessors(kmer, k) {
+				if set[kmers.Canonical(p, k)] {
+					in++
+					if g.InEdges(n)>>uint(b)&1 != 1 {
+						t.Errorf("InEdges error: %s", kmers.Decode(kmer, k))
+					}
+				}
+			}
+			if g.OutDegree(n) != out || g.InDegree(n) != in {
+				t.Errorf("degree error: %s, expected %d/%d, returned %d/%d",
+					kmers.Decode(kmer, k), in, out, g.InDegree(n), g.OutDegree(n))
+			}
+			for _, s := range g.Successors(n) {
+				found := false
+				for _, p := range g.Predecessors(s) {
+					if p == n {
+						found = true
+					}
+				}
+				if !found {
+					t.Errorf("%s should be a predecessor of %s", kmers.Decode(kmer, k), kmers.Decode(s.Kmer(k), k))
+				}
+			}
+		}
+	}
+
+	// traverse the path of the sequence
+	start, ok := g.Node(codes[0])
+	if !ok {
+		t.Errorf("Node error: %s", seq[:k])
+	}
+	var n int
+	g.Traverse(start, func(node Node, depth int) bool {
+		n++
+		return true
+	})
+	distinct := make(map[uint64]bool)
+	for _, code := range codes {
+		distinct[code] = true
+	}
+	if n < len(distinct) {
+		t.Errorf("Traverse error: only %d nodes visited", n)
+	}
+
+	// from sorted codes
+	g2, err := NewFromSorted(g.Nodes(), k)
+	if err != nil || g2.Len() != g.Len() {
+		t.Errorf("NewFromSorted error: %s", err)
+	}
+	if _, err = NewFromSorted(kmers.CodeSlice{3, 1}, k); err != ErrUnsortedCodes {
+		t.Errorf("NewFromSorted should fail for unsorted codes")
+	}
+
+	// from counts
+	counts := make(map[uint64]uint32)
+	for _, code := range codes {
+		counts[code]++
+		counts[kmers.RevComp(code, k)]++
+	}
+	g3, _ := NewFromCounts(counts, k)
+	if g3.Len() != g.Len() {
+		t.Errorf("NewFromCounts error: expected %d nodes, returned %d", g.Len(), g3.Len())
+	}
+	if c := g3.Count(start); c < 2 {
+		t.Errorf("Count error: %d", c)
+	}
+}
+
+func TestNewFromCountsSaturation(t *testing.T) {
+	k := 5
+	code, _ := kmers.Encode([]byte("ACGGT"))
+	counts := map[uint64]uint32{
+		code:                       math.MaxUint32 - 10,
+		kmers.MustRevComp(code, k): math.MaxUint32 - 10,
+	}
+	g, _ := NewFromCounts(counts, k)
+	if g.Len() != 1 {
+		t.Errorf("NewFromCounts error: expected 1 node, returned %d", g.Len())
+	}
+	if c := g.Count(Node{Code: kmers.MustCanonical(code, k)}); c != math.MaxUint32 {
+		t.Errorf("NewFromCounts error: count should saturate, returned %d", c)
+	}
+}
@@ -0,0 +1,249 @@
+// Copyright © 2018-2021 Wei Shen <shenwei356@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dbg
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+
+	"github.com/shenwei356/kmers"
+)
+
+// Unitig is a maximal non-branching path of the graph.
+type Unitig struct {
+	Seq   []byte // sequence of the path
+	Kmers int    // number of k-mers
+	Count uint64 // sum of abundances of k-mers
+
+	first Node // the first node on the path
+	last  Node // the last node on the path
+}
+
+// Link is a (k-1)-overlap from the end of a unitig to the start of another one,
+// and unitigs can be on the reverse complement strand.
+// A link from A+ to B- is the same as the one from B+ to A-, and only one of them is kept.
+type Link struct {
+	From   int // index of the unitig
+	FromRC bool
+	To     int
+	ToRC   bool
+}
+
+// UnitigGraph is the compacted de Bruijn graph.
+type UnitigGraph struct {
+	K       int
+	Unitigs []Unitig
+	Links   []Link
+}
+
+// Compact compacts non-branching paths into unitigs, and computes links between them.
+func (g *Graph) Compact() *UnitigGraph {
+	k := g.k
+	ug := &UnitigGraph{K: k}
+	ids := make([]int, len(g.nodes)) // unitig index + 1 of nodes
+	var path []Node
+	var n, next Node
+	var j int
+	for i, code := range g.nodes {
+		if ids[i] > 0 {
+			continue
+		}
+		id := len(ug.Unitigs) + 1
+		ids[i] = id
+
+		// extend forward
+		path = append(path[:0], Node{code, false})
+		n = path[0]
+		for g.OutDegree(n) == 1 {
+			next = g.Successors(n)[0]
+			if g.InDegree(next) != 1 {
+				break
+			}
+			j = g.Index(next.Code)
+			if ids[j] > 0 { // cycles or hairpins
+				break
+			}
+			ids[j] = id
+			path = append(path, next)
+			n = next
+		}
+
+		// extend backward, the path is reversed for now
+		reverse(path)
+		n = path[len(path)-1]
+		for g.InDegree(n) == 1 {
+			next = g.Predecessors(n)[0]
+			if g.OutDegree(next) != 1 {
+				break
+			}
+			j = g.Index(next.Code)
+			if ids[j] > 0 {
+				break
+			}
+			ids[j] = id
+			path = append(path, next)
+			n = next
+		}
+		reverse(path)
+
+		ug.Unitigs = append(ug.Unitigs, g.newUnitig(path))
+	}
+
+	// links
+	var fromRC bool
+	var u *Unitig
+	var to Link
+	for i := range ug.Unitigs {
+		u = &ug.Unitigs[i]
+		for _, fromRC = range []bool{false, true} {
+			n = u.last
+			if fromRC {
+				n = u.first.Flip()
+			}
+			for _, s := range g.Successors(n) {
+				j = ids[g.Index(s.Code)] - 1
+				if ug.Unitigs[j].first == s {
+					to = Link{i, fromRC, j, false}
+				} else if ug.Unitigs[j].last == s.Flip() {
+					to = Link{i, fromRC, j, true}
+				} else { // not possible for maximal unitigs
+					continue
+				}
+				// only keep one of the two equivalent links
+				if linkKey(to.From, to.FromRC) <= linkKey(to.To, !to.ToRC) {
+					ug.Links = append(ug.Links, to)
+				}
+			}
+		}
+	}
+	return ug
+}
+
+func linkKey(id int, rc bool) int {
+	if rc {
+		return id<<1 | 1
+	}
+	return id << 1
+}
+
+func reverse(path []Node) {
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+}
+
+func (g *Graph) newUnitig(path []Node) Unitig {
+	k := g.k
+	u := Unitig{
+		Seq:   kmers.MustDecode(path[0].Kmer(k), k),
+		Kmers: len(path),
+		first: path[0],
+		last:  path[len(path)-1],
+	}
+	for i, n := range path {
+		if i > 0 {
+			u.Seq = append(u.Seq, "ACGT"[n.Kmer(k)&3])
+		}
+		u.Count += uint64(g.Count(n))
+	}
+	return u
+}
+
+func strand(rc bool) byte {
+	if rc {
+		return '-'
+	}
+	return '+'
+}
+
+// WriteGFA writes unitigs and links in GFA1 format.
+// Segments are named by 0-based indexes, with tags of length (LN) and k-mer counts (KC).
+func (ug *UnitigGraph) WriteGFA(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	bw.WriteString("H\tVN:Z:1.0\n")
+
+	buf := make([]byte, 0, 64)
+	for i, u := range ug.Unitigs {
+		buf = append(buf[:0], "S\t"...)
+		buf = strconv.AppendInt(buf, int64(i), 10)
+		buf = append(buf, '\t')
+		bw.Write(buf)
+		bw.Write(u.Seq)
+		buf = append(buf[:0], "\tLN:i:"...)
+		buf = strconv.AppendInt(buf, int64(len(u.Seq)), 10)
+		buf = append(buf, "\tKC:i:"...)
+		buf = strconv.AppendUint(buf, u.Count, 10)
+		buf = append(buf, '\n')
+		bw.Write(buf)
+	}
+
+	for _, l := range ug.Links {
+		buf = append(buf[:0], "L\t"...)
+		buf = strconv.AppendInt(buf, int64(l.From), 10)
+		buf = append(buf, '\t', strand(l.FromRC), '\t')
+		buf = strconv.AppendInt(buf, int64(l.To), 10)
+		buf = append(buf, '\t', strand(l.ToRC), '\t')
+		buf = strconv.AppendInt(buf, int64(ug.K-1), 10)
+		buf = append(buf, "M\n"...)
+		bw.Write(buf)
+	}
+	return bw.Flush()
+}
+
+// WriteFASTA writes unitigs in FASTA format, with length (LN), k-mer counts (KC)
+// and links (L) in headers like BCALM 2, e.g.,
+//
+//	>0 LN:i:35 KC:i:5 L:+:2:- L:-:1:+
+//
+// where "L:+:2:-" means the end of unitig 0 overlaps the start of
+// the reverse complement of unitig 2.
+func (ug *UnitigGraph) WriteFASTA(w io.Writer) error {
+	links := make([][]Link, len(ug.Unitigs))
+	for _, l := range ug.Links {
+		links[l.From] = append(links[l.From], l)
+		if l.From != l.To || l.FromRC != !l.ToRC { // the equivalent link
+			links[l.To] = append(links[l.To], Link{l.To, !l.ToRC, l.From, !l.FromRC})
+		}
+	}
+
+	bw := bufio.NewWriter(w)
+	buf := make([]byte, 0, 128)
+	for i, u := range ug.Unitigs {
+		buf = append(buf[:0], '>')
+		buf = strconv.AppendInt(buf, int64(i), 10)
+		buf = append(buf, " LN:i:"...)
+		buf = strconv.AppendInt(buf, int64(len(u.Seq)), 10)
+		buf = append(buf, " KC:i:"...)
+		buf = strconv.AppendUint(buf, u.Count, 10)
+		for _, l := range links[i] {
+			buf = append(buf, " L:"...)
+			buf = append(buf, strand(l.FromRC), ':')
+			buf = strconv.AppendInt(buf, int64(l.To), 10)
+			buf = append(buf, ':', strand(l.ToRC))
+		}
+		buf = append(buf, '\n')
+		bw.Write(buf)
+		bw.Write(u.Seq)
+		bw.WriteByte('\n')
+	}
+	return bw.Flush()
+}
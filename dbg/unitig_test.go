@@ -0,0 +1,122 @@
+// Copyright © 2018-2021 Wei Shen <shenwei356@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dbg
+
+import (
+	"bytes"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/shenwei356/kmers"
+)
+
+func randomSeq(rng *rand.Rand, n int) string {
+	s := make([]byte, n)
+	for i := range s {
+		s[i] = "ACGT"[rng.Intn(4)]
+	}
+	return string(s)
+}
+
+func revComp(s []byte) []byte {
+	rc := make([]byte, len(s))
+	for i, b := range s {
+		rc[len(s)-1-i] = map[byte]byte{'A': 'T', 'C': 'G', 'G': 'C', 'T': 'A'}[b]
+	}
+	return rc
+}
+
+func TestCompact(t *testing.T) {
+	k := 15
+	rng := rand.New(rand.NewSource(1))
+
+	// a linear sequence
+	seq := randomSeq(rng, 200)
+	g, _ := New(kmersOf(seq, k), k)
+	ug := g.Compact()
+	if len(ug.Unitigs) != 1 {
+		t.Fatalf("Compact error: expected 1 unitig, returned %d", len(ug.Unitigs))
+	}
+	if s := ug.Unitigs[0].Seq; string(s) != seq && string(revComp(s)) != seq {
+		t.Errorf("Compact error: expected %s, returned %s", seq, s)
+	}
+
+	// branches and a reverse complement join
+	x, m, y, z, w, q := randomSeq(rng, 40), randomSeq(rng, 40), randomSeq(rng, 40), randomSeq(rng, 40), randomSeq(rng, 40), randomSeq(rng, 40)
+	seqs := []string{x + m + y, z + m + w, string(revComp([]byte(y))) + q}
+	var codes []uint64
+	for _, s := range seqs {
+		codes = append(codes, kmersOf(s, k)...)
+	}
+	g, _ = New(codes, k)
+	ug = g.Compact()
+
+	var n int
+	for _, u := range ug.Unitigs {
+		n += u.Kmers
+		if u.Kmers != len(u.Seq)-k+1 || u.Count != uint64(u.Kmers) {
+			t.Errorf("Compact error: wrong number of k-mers: %d, %d", u.Kmers, u.Count)
+		}
+		for _, code := range kmersOf(string(u.Seq), k) {
+			if _, ok := g.Node(code); !ok {
+				t.Errorf("Compact error: unknown k-mer %s", kmers.Decode(code, k))
+			}
+		}
+	}
+	if n != g.Len() {
+		t.Errorf("Compact error: expected %d k-mers, returned %d", g.Len(), n)
+	}
+	// x, z, m, the junction of m and y, y, w, q
+	if len(ug.Unitigs) != 7 || len(ug.Links) != 6 {
+		t.Errorf("Compact error: %d unitigs and %d links", len(ug.Unitigs), len(ug.Links))
+	}
+
+	for _, l := range ug.Links {
+		from, to := ug.Unitigs[l.From].Seq, ug.Unitigs[l.To].Seq
+		if l.FromRC {
+			from = revComp(from)
+		}
+		if l.ToRC {
+			to = revComp(to)
+		}
+		if !bytes.Equal(from[len(from)-k+1:], to[:k-1]) {
+			t.Errorf("Compact error: wrong link %v", l)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := ug.WriteGFA(&buf); err != nil {
+		t.Errorf("WriteGFA error: %s", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 1+len(ug.Unitigs)+len(ug.Links) || !strings.HasPrefix(lines[1], "S\t0\t") {
+		t.Errorf("WriteGFA error: %s", buf.String())
+	}
+
+	buf.Reset()
+	if err := ug.WriteFASTA(&buf); err != nil {
+		t.Errorf("WriteFASTA error: %s", err)
+	}
+	if c := strings.Count(buf.String(), " L:"); c < len(ug.Links) {
+		t.Errorf("WriteFASTA error: %s", buf.String())
+	}
+}
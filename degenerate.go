@@ -0,0 +1,133 @@
+// Copyright © 2018-2021 Wei Shen <shenwei356@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package kmers
+
+import (
+	"errors"
+	"math"
+)
+
+// ErrTooManyExpansions means the number of k-mers a degenerate k-mer
+// stands for exceeds the limit.
+var ErrTooManyExpansions = errors.New("kmers: too many expansions of degenerate k-mer")
+
+// base2bits maps IUPAC symbols to all the bases they stand for,
+// with A, C, G and T in the 1st, 2nd, 3rd and 4th lowest bit respectively.
+// 0 means illegal bases.
+var base2bits [256]uint8
+
+func init() {
+	iupac := map[byte]uint8{
+		'A': 1, 'C': 2, 'G': 4, 'T': 8, 'U': 8,
+		'M': 1 | 2, 'R': 1 | 4, 'W': 1 | 8, 'S': 2 | 4, 'Y': 2 | 8, 'K': 4 | 8,
+		'V': 1 | 2 | 4, 'H': 1 | 2 | 8, 'D': 1 | 4 | 8, 'B': 2 | 4 | 8,
+		'N': 1 | 2 | 4 | 8,
+	}
+	for b, bits := range iupac {
+		base2bits[b] = bits
+		base2bits[b+32] = bits // lower case
+	}
+}
+
+// NumExpansions returns the number of k-mers a degenerate k-mer stands for.
+// The number is capped at max+1 if max > 0, or math.MaxInt otherwise.
+func NumExpansions(kmer []byte, max int) (int, error) {
+	if len(kmer) == 0 || len(kmer) > 32 {
+		return 0, ErrKOverflow
+	}
+
+	n := 1
+	var bits uint8
+	var m int
+	for _, b := range kmer {
+		bits = base2bits[b]
+		if bits == 0 {
+			return 0, ErrIllegalBase
+		}
+		m = int(bits&1 + bits>>1&1 + bits>>2&1 + bits>>3)
+		if n > math.MaxInt/m {
+			n = math.MaxInt
+		} else {
+			n *= m
+		}
+		if max > 0 && n > max {
+			return max + 1, nil
+		}
+	}
+	return n, nil
+}
+
+// maxPreallocExpansions is the maximum capacity preallocated in ExpandDegenerate.
+const maxPreallocExpansions = 1 << 16
+
+// ExpandDegenerate returns codes of all k-mers a degenerate k-mer stands for,
+// in lexicographic order.
+// Unlike Encode, all bases of degenerate bases are kept, see the table in Encode.
+// ErrTooManyExpansions is returned if the number of k-mers is bigger than max,
+// and max <= 0 means no limit, where ErrTooManyExpansions is still returned
+// if the number exceeds math.MaxInt.
+func ExpandDegenerate(kmer []byte, max int) ([]uint64, error) {
+	n, err := NumExpansions(kmer, max)
+	if err != nil {
+		return nil, err
+	}
+	if (max > 0 && n > max) || n == math.MaxInt {
+		return nil, ErrTooManyExpansions
+	}
+
+	if n > maxPreallocExpansions {
+		n = maxPreallocExpansions
+	}
+	codes := make([]uint64, 0, n)
+	expandDegenerate(kmer, 0, 0, func(code uint64) {
+		codes = append(codes, code)
+	})
+	return codes, nil
+}
+
+// ExpandDegenerateFunc is similar to ExpandDegenerate,
+// but streams codes through a callback function instead of returning a slice.
+func ExpandDegenerateFunc(kmer []byte, max int, fn func(code uint64)) error {
+	n, err := NumExpansions(kmer, max)
+	if err != nil {
+		return err
+	}
+	if (max > 0 && n > max) || n == math.MaxInt {
+		return ErrTooManyExpansions
+	}
+
+	expandDegenerate(kmer, 0, 0, fn)
+	return nil
+}
+
+// expandDegenerate expands bases from position i, with code of former bases.
+func expandDegenerate(kmer []byte, i int, code uint64, fn func(code uint64)) {
+	if i == len(kmer) {
+		fn(code)
+		return
+	}
+	bits := base2bits[kmer[i]]
+	for v := uint64(0); v < 4; v++ {
+		if bits>>v&1 == 1 {
+			expandDegenerate(kmer, i+1, code<<2|v, fn)
+		}
+	}
+}
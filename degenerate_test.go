@@ -0,0 +1,93 @@
+// Copyright © 2018-2021 Wei Shen <shenwei356@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package kmers
+
+import (
+	"math"
+	"math/bits"
+	"testing"
+)
+
+func TestExpandDegenerate(t *testing.T) {
+	codes, err := ExpandDegenerate([]byte("ARYn"), 0)
+	if err != nil {
+		t.Errorf("ExpandDegenerate error: %s", err)
+	}
+	expected := []string{
+		"AACA", "AACC", "AACG", "AACT", "AATA", "AATC", "AATG", "AATT",
+		"AGCA", "AGCC", "AGCG", "AGCT", "AGTA", "AGTC", "AGTG", "AGTT",
+	}
+	if len(codes) != len(expected) {
+		t.Fatalf("ExpandDegenerate error: expected %d k-mers, returned %d", len(expected), len(codes))
+	}
+	for i, code := range codes {
+		if s := string(Decode(code, 4)); s != expected[i] {
+			t.Errorf("ExpandDegenerate error: expected %s, returned %s", expected[i], s)
+		}
+	}
+
+	var n int
+	err = ExpandDegenerateFunc([]byte("ACGT"), 1, func(code uint64) {
+		n++
+		if s := string(Decode(code, 4)); s != "ACGT" {
+			t.Errorf("ExpandDegenerateFunc error: expected %s, returned %s", "ACGT", s)
+		}
+	})
+	if err != nil || n != 1 {
+		t.Errorf("ExpandDegenerateFunc error: %s, %d", err, n)
+	}
+
+	if _, err = ExpandDegenerate([]byte("NNNNNNNNNNNNNNNNNNNNNNNNNNNNNNNN"), 1000); err != ErrTooManyExpansions {
+		t.Errorf("ExpandDegenerate should fail for too many expansions")
+	}
+	// no limit
+	if _, err = ExpandDegenerate([]byte("NNNNNNNNNNNNNNNNNNNNNNNNNNNNNNNN"), 0); err != ErrTooManyExpansions {
+		t.Errorf("ExpandDegenerate should fail for too many expansions without limit")
+	}
+	if err = ExpandDegenerateFunc([]byte("NNNNNNNNNNNNNNNNNNNNNNNNNNNNNNNN"), 0, func(uint64) {}); err != ErrTooManyExpansions {
+		t.Errorf("ExpandDegenerateFunc should fail for too many expansions without limit")
+	}
+	if codes, err := ExpandDegenerate([]byte("NNNNNNNNNA"), 0); err != nil || len(codes) != 1<<18 {
+		t.Errorf("ExpandDegenerate error: %d codes returned", len(codes))
+	}
+	if _, err = ExpandDegenerate([]byte("ACGX"), 0); err != ErrIllegalBase {
+		t.Errorf("ExpandDegenerate should fail for illegal bases")
+	}
+}
+
+func TestNumExpansions(t *testing.T) {
+	if n, err := NumExpansions([]byte("ARYn"), 0); err != nil || n != 16 {
+		t.Errorf("NumExpansions error: expected %d, returned %d", 16, n)
+	}
+	if n, _ := NumExpansions([]byte("ARYn"), 10); n != 11 {
+		t.Errorf("NumExpansions error: expected %d, returned %d", 11, n)
+	}
+
+	// 4^31 fits in 64-bit int, while 4^32 does not
+	if bits.UintSize == 64 {
+		if n, _ := NumExpansions([]byte("NNNNNNNNNNNNNNNNNNNNNNNNNNNNNNNA"), 0); uint64(n) != 1<<62 {
+			t.Errorf("NumExpansions error: expected %d, returned %d", uint64(1<<62), n)
+		}
+	}
+	if n, _ := NumExpansions([]byte("NNNNNNNNNNNNNNNNNNNNNNNNNNNNNNNN"), 0); n != math.MaxInt {
+		t.Errorf("NumExpansions error: expected %d, returned %d", math.MaxInt, n)
+	}
+}
@@ -0,0 +1,207 @@
+// Copyright © 2018-2021 Wei Shen <shenwei356@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package kmers
+
+import (
+	"errors"
+	"math"
+	"sort"
+)
+
+// ErrInvalidScale means the scale is 0.
+var ErrInvalidScale = errors.New("kmers: invalid scale")
+
+// ErrScaleMismatch means scales of two sketches are different.
+var ErrScaleMismatch = errors.New("kmers: scale mismatch")
+
+// FracMinHash is a FracMinHash (scaled MinHash) sketch of canonical k-mers,
+// which keeps hash values <= max/scale, with optional abundances.
+// k-mer codes are hashed with an invertible 64-bit mixer.
+type FracMinHash struct {
+	k              int
+	scale          uint64
+	maxHash        uint64
+	trackAbundance bool
+
+	hashes map[uint64]uint32 // hash -> abundance
+}
+
+// NewFracMinHash creates a FracMinHash sketch.
+func NewFracMinHash(k int, scale uint64, trackAbundance bool) (*FracMinHash, error) {
+	if k <= 0 || k > 32 {
+		return nil, ErrKOverflow
+	}
+	if scale == 0 {
+		return nil, ErrInvalidScale
+	}
+	return &FracMinHash{
+		k:              k,
+		scale:          scale,
+		maxHash:        math.MaxUint64 / scale,
+		trackAbundance: trackAbundance,
+		hashes:         make(map[uint64]uint32, 1024),
+	}, nil
+}
+
+// K returns the k-mer size.
+func (s *FracMinHash) K() int {
+	return s.k
+}
+
+// Scale returns the scale.
+func (s *FracMinHash) Scale() uint64 {
+	return s.scale
+}
+
+// MaxHash returns the maximum hash value kept.
+func (s *FracMinHash) MaxHash() uint64 {
+	return s.maxHash
+}
+
+// Add adds a canonical k-mer code, e.g., from Canonical() or CanonicalKmerIterator.
+func (s *FracMinHash) Add(code uint64) {
+	h := hash64(code, 1<<64-1)
+	if h > s.maxHash {
+		return
+	}
+	if s.trackAbundance {
+		if n := s.hashes[h]; n < math.MaxUint32 {
+			s.hashes[h] = n + 1
+		}
+	} else {
+		s.hashes[h] = 1
+	}
+}
+
+// AddSeq adds all canonical k-mers of a sequence.
+func (s *FracMinHash) AddSeq(seq []byte) error {
+	iter, err := NewCanonicalKmerIterator(seq, s.k)
+	if err != nil {
+		return err
+	}
+	var code uint64
+	var ok bool
+	for {
+		code, _, _, ok = iter.Next()
+		if !ok {
+			break
+		}
+		s.Add(code)
+	}
+	return iter.Err()
+}
+
+// Len returns the number of hashes.
+func (s *FracMinHash) Len() int {
+	return len(s.hashes)
+}
+
+// Hashes returns sorted hash values.
+func (s *FracMinHash) Hashes() []uint64 {
+	hashes := make(CodeSlice, 0, len(s.hashes))
+	for h := range s.hashes {
+		hashes = append(hashes, h)
+	}
+	sort.Sort(hashes)
+	return hashes
+}
+
+// Kmers returns k-mer codes recovered from sorted hash values.
+func (s *FracMinHash) Kmers() []uint64 {
+	codes := s.Hashes()
+	for i, h := range codes {
+		codes[i] = unhash64(h, 1<<64-1)
+	}
+	return codes
+}
+
+// Abundance returns the abundance of a hash value, 0 for absent ones.
+// It's always 1 for present ones if abundances are not tracked.
+// Abundances saturate at math.MaxUint32.
+func (s *FracMinHash) Abundance(hash uint64) uint32 {
+	return s.hashes[hash]
+}
+
+// Merge merges another sketch into this one, abundances are summed up,
+// saturating at math.MaxUint32.
+func (s *FracMinHash) Merge(s2 *FracMinHash) error {
+	if s.k != s2.k {
+		return ErrKMismatch
+	}
+	if s.scale != s2.scale {
+		return ErrScaleMismatch
+	}
+	for h, n := range s2.hashes {
+		if s.trackAbundance {
+			if m := s.hashes[h]; n > math.MaxUint32-m {
+				s.hashes[h] = math.MaxUint32
+			} else {
+				s.hashes[h] = m + n
+			}
+		} else {
+			s.hashes[h] = 1
+		}
+	}
+	return nil
+}
+
+// Intersection returns the number of shared hashes.
+func (s *FracMinHash) Intersection(s2 *FracMinHash) (int, error) {
+	if s.k != s2.k {
+		return 0, ErrKMismatch
+	}
+	if s.scale != s2.scale {
+		return 0, ErrScaleMismatch
+	}
+	a, b := s.hashes, s2.hashes
+	if len(b) < len(a) {
+		a, b = b, a
+	}
+	var n int
+	for h := range a {
+		if _, ok := b[h]; ok {
+			n++
+		}
+	}
+	return n, nil
+}
+
+// Containment estimates the fraction of k-mers of this sketch contained in another one.
+func (s *FracMinHash) Containment(s2 *FracMinHash) (float64, error) {
+	n, err := s.Intersection(s2)
+	if err != nil || len(s.hashes) == 0 {
+		return 0, err
+	}
+	return float64(n) / float64(len(s.hashes)), nil
+}
+
+// Jaccard estimates the Jaccard index of k-mers of the two sketches.
+func (s *FracMinHash) Jaccard(s2 *FracMinHash) (float64, error) {
+	n, err := s.Intersection(s2)
+	if err != nil {
+		return 0, err
+	}
+	union := len(s.hashes) + len(s2.hashes) - n
+	if union == 0 {
+		return 0, nil
+	}
+	return float64(n) / float64(union), nil
+}
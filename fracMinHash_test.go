@@ -0,0 +1,119 @@
+// Copyright © 2018-2021 Wei Shen <shenwei356@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package kmers
+
+import (
+	"math"
+	"testing"
+)
+
+func TestFracMinHash(t *testing.T) {
+	k, scale := 21, uint64(10)
+	shared := randomSeq(50000)
+	seq1 := append(append([]byte{}, shared...), randomSeq(50000)...)
+	seq2 := append(revCompBytes(shared), randomSeq(150000)...)
+
+	s1, _ := NewFracMinHash(k, scale, true)
+	s2, _ := NewFracMinHash(k, scale, false)
+	if err := s1.AddSeq(seq1); err != nil {
+		t.Fatalf("AddSeq error: %s", err)
+	}
+	s2.AddSeq(seq2)
+
+	// about 1/scale of k-mers are kept
+	n := len(seq1) - k + 1
+	if r := float64(s1.Len()) * float64(scale) / float64(n); math.Abs(r-1) > 0.1 {
+		t.Errorf("FracMinHash error: %d hashes kept for %d k-mers", s1.Len(), n)
+	}
+	for _, h := range s1.Hashes() {
+		if h > s1.MaxHash() {
+			t.Errorf("FracMinHash error: hash %d > max hash %d", h, s1.MaxHash())
+		}
+	}
+
+	// shared k-mers are on different strands.
+	c, err := s1.Containment(s2)
+	if err != nil || math.Abs(c-0.5) > 0.05 {
+		t.Errorf("Containment error: expected ~%f, returned %f", 0.5, c)
+	}
+	j, _ := s1.Jaccard(s2)
+	if math.Abs(j-0.2) > 0.05 {
+		t.Errorf("Jaccard error: expected ~%f, returned %f", 0.2, j)
+	}
+
+	// abundance and merging
+	h := s1.Hashes()[0]
+	s3, _ := NewFracMinHash(k, scale, true)
+	s3.AddSeq(seq1)
+	if err = s3.Merge(s1); err != nil {
+		t.Errorf("Merge error: %s", err)
+	}
+	if s3.Len() != s1.Len() || s3.Abundance(h) != 2*s1.Abundance(h) {
+		t.Errorf("Merge error: %d hashes, abundance %d", s3.Len(), s3.Abundance(h))
+	}
+	if err = s3.Merge(s2); err != nil || s3.Len() < s2.Len() {
+		t.Errorf("Merge error: %d hashes", s3.Len())
+	}
+
+	s4, _ := NewFracMinHash(k, 100, false)
+	if _, err = s1.Jaccard(s4); err != ErrScaleMismatch {
+		t.Errorf("Jaccard should fail for different scales")
+	}
+}
+
+func TestFracMinHashKmers(t *testing.T) {
+	k := 21
+	seq := randomSeq(10000)
+	s, _ := NewFracMinHash(k, 10, false)
+	s.AddSeq(seq)
+
+	kmers := make(map[uint64]struct{}, len(seq))
+	for i := 0; i+k <= len(seq); i++ {
+		code, _ := Encode(seq[i : i+k])
+		kmers[MustCanonical(code, k)] = struct{}{}
+	}
+	for _, code := range s.Kmers() {
+		if _, ok := kmers[code]; !ok {
+			t.Errorf("Kmers error: %s is not a canonical k-mer of the sequence", MustDecode(code, k))
+		}
+	}
+}
+
+func TestFracMinHashAbundanceSaturation(t *testing.T) {
+	s1, _ := NewFracMinHash(21, 1, true)
+	s2, _ := NewFracMinHash(21, 1, true)
+	s1.Add(1)
+	h := s1.Hashes()[0]
+	s1.hashes[h] = math.MaxUint32 - 1
+	s1.Add(1)
+	s1.Add(1)
+	if n := s1.Abundance(h); n != math.MaxUint32 {
+		t.Errorf("Add error: abundance should saturate, returned %d", n)
+	}
+
+	s2.Add(1)
+	s2.Add(1)
+	s1.hashes[h] = math.MaxUint32 - 1
+	s1.Merge(s2)
+	if n := s1.Abundance(h); n != math.MaxUint32 {
+		t.Errorf("Merge error: abundance should saturate, returned %d", n)
+	}
+}
@@ -0,0 +1,123 @@
+// Copyright © 2018-2021 Wei Shen <shenwei356@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package kmers
+
+// Hash64 hashes a k-mer code with Thomas Wang's invertible integer hash
+// function, keeping the value in 2k bits. It panics if k is not in [1, 32].
+// The code can be recovered with Unhash64.
+// Note that bits are poorly mixed for k < 16, as the shifts of 24, 28 and 31
+// bits have little or no effect on values of 2k bits.
+func Hash64(code uint64, k int) uint64 {
+	return hash64(code, hashMask(k))
+}
+
+// Unhash64 is the inverse of Hash64.
+func Unhash64(hash uint64, k int) uint64 {
+	return unhash64(hash, hashMask(k))
+}
+
+// HashSplitMix64 hashes a k-mer code with the finalizer of splitmix64,
+// keeping the value in 2k bits. It panics if k is not in [1, 32].
+// The code can be recovered with UnhashSplitMix64.
+// Note that bits are poorly mixed for k < 16.
+func HashSplitMix64(code uint64, k int) uint64 {
+	mask := hashMask(k)
+	code ^= code >> 30
+	code = (code * 0xbf58476d1ce4e5b9) & mask
+	code ^= code >> 27
+	code = (code * 0x94d049bb133111eb) & mask
+	code ^= code >> 31
+	return code
+}
+
+// UnhashSplitMix64 is the inverse of HashSplitMix64.
+func UnhashSplitMix64(hash uint64, k int) uint64 {
+	mask := hashMask(k)
+	hash = unxorshift(hash, 31)
+	hash = (hash * 0x319642b2d24d8ec3) & mask // inverse of 0x94d049bb133111eb
+	hash = unxorshift(hash, 27)
+	hash = (hash * 0x96de1b173f119089) & mask // inverse of 0xbf58476d1ce4e5b9
+	hash = unxorshift(hash, 30)
+	return hash
+}
+
+// hashMask returns the mask of 2k bits.
+func hashMask(k int) uint64 {
+	if k <= 0 || k > 32 {
+		panic(ErrKOverflow)
+	}
+	return (1 << uint(k<<1)) - 1
+}
+
+// hash64 is Thomas Wang's invertible integer hash function, modified
+// to keep values in the bits of mask, from minimap2 (https://github.com/lh3/minimap2).
+func hash64(key uint64, mask uint64) uint64 {
+	key = (^key + (key << 21)) & mask // key = (key << 21) - key - 1
+	key = key ^ key>>24
+	key = ((key + (key << 3)) + (key << 8)) & mask // key * 265
+	key = key ^ key>>14
+	key = ((key + (key << 2)) + (key << 4)) & mask // key * 21
+	key = key ^ key>>28
+	key = (key + (key << 31)) & mask
+	return key
+}
+
+// unhash64 is the inverse of hash64,
+// from https://gist.github.com/lh3/974ced188be2f90422cc .
+func unhash64(key uint64, mask uint64) uint64 {
+	var tmp uint64
+
+	// invert key = key + (key << 31)
+	tmp = key - (key << 31)
+	key = (key - (tmp << 31)) & mask
+
+	// invert key = key ^ (key >> 28)
+	key = unxorshift(key, 28)
+
+	// invert key *= 21
+	key = (key * 14933078535860113213) & mask
+
+	// invert key = key ^ (key >> 14)
+	key = unxorshift(key, 14)
+
+	// invert key *= 265
+	key = (key * 15244667743933553977) & mask
+
+	// invert key = key ^ (key >> 24)
+	key = unxorshift(key, 24)
+
+	// invert key = (^key) + (key << 21)
+	tmp = ^key
+	tmp = ^(key - (tmp << 21))
+	tmp = ^(key - (tmp << 21))
+	key = ^(key - (tmp << 21)) & mask
+
+	return key
+}
+
+// unxorshift inverts x ^= x >> s.
+func unxorshift(x uint64, s uint) uint64 {
+	y := x
+	for i := s; i < 64; i += s {
+		y = x ^ y>>s
+	}
+	return y
+}
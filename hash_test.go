@@ -0,0 +1,78 @@
+// Copyright © 2018-2021 Wei Shen <shenwei356@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package kmers
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestHashUnhash(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	var code, h, mask uint64
+	for k := 1; k <= 32; k++ {
+		mask = (1 << uint(k<<1)) - 1
+		for i := 0; i < 1000; i++ {
+			code = rng.Uint64() & mask
+
+			h = Hash64(code, k)
+			if h > mask {
+				t.Fatalf("Hash64 error: k=%d, %d out of range", k, h)
+			}
+			if c := Unhash64(h, k); c != code {
+				t.Fatalf("Unhash64 error: k=%d, expected %d, returned %d", k, code, c)
+			}
+
+			h = HashSplitMix64(code, k)
+			if h > mask {
+				t.Fatalf("HashSplitMix64 error: k=%d, %d out of range", k, h)
+			}
+			if c := UnhashSplitMix64(h, k); c != code {
+				t.Fatalf("UnhashSplitMix64 error: k=%d, expected %d, returned %d", k, code, c)
+			}
+		}
+	}
+
+	// hash functions should be bijections
+	k := 5
+	seen := make(map[uint64]struct{}, 1<<uint(k<<1))
+	for code = 0; code < 1<<uint(k<<1); code++ {
+		seen[Hash64(code, k)] = struct{}{}
+	}
+	if len(seen) != 1<<uint(k<<1) {
+		t.Errorf("Hash64 error: not a bijection for k=%d", k)
+	}
+}
+
+func TestHashKOverflow(t *testing.T) {
+	for _, k := range []int{0, 33} {
+		for _, f := range []func(uint64, int) uint64{Hash64, Unhash64, HashSplitMix64, UnhashSplitMix64} {
+			func() {
+				defer func() {
+					if r := recover(); r != ErrKOverflow {
+						t.Errorf("hash functions should panic with ErrKOverflow for k=%d", k)
+					}
+				}()
+				f(1, k)
+			}()
+		}
+	}
+}
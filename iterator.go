@@ -0,0 +1,210 @@
+// Copyright © 2018-2021 Wei Shen <shenwei356@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package kmers
+
+// KmerIterator iterates all k-mers of a sequence with a rolling code,
+// i.e., each step only shifts in one base like MustEncodeFromFormerKmer.
+// Illegal bases are skipped, and the iteration restarts from the next base,
+// unless the encode policy is Strict, where the iteration stops with an error.
+// The default policy is Lenient, and it can be changed with SetPolicy.
+type KmerIterator struct {
+	seq    []byte
+	k      int
+	mask   uint64
+	policy EncodePolicy
+	table  *[256]uint64 // encoding table of the policy
+
+	i    int    // index of the next base to read
+	n    int    // number of consecutive legal bases, up to k
+	code uint64 // code of the last n bases
+	err  error
+}
+
+// NewKmerIterator returns a KmerIterator for the sequence.
+func NewKmerIterator(seq []byte, k int) (*KmerIterator, error) {
+	if k <= 0 || k > 32 {
+		return nil, ErrKOverflow
+	}
+	return &KmerIterator{seq: seq, k: k, mask: (1 << uint(k<<1)) - 1, table: &base2bit}, nil
+}
+
+// SetPolicy sets the policy for handling degenerate bases.
+func (iter *KmerIterator) SetPolicy(policy EncodePolicy) {
+	iter.policy = policy
+	iter.table = policy.table()
+}
+
+// Reset resets the iterator with a new sequence, so it can be reused.
+func (iter *KmerIterator) Reset(seq []byte) {
+	iter.seq = seq
+	iter.i = 0
+	iter.n = 0
+	iter.code = 0
+	iter.err = nil
+}
+
+// K returns the k-mer size.
+func (iter *KmerIterator) K() int {
+	return iter.k
+}
+
+// Err returns the error that stopped the iteration,
+// i.e., ErrIllegalBase in the Strict encode policy.
+func (iter *KmerIterator) Err() error {
+	return iter.err
+}
+
+// Next returns the code of the next k-mer and its position (0-based) in the sequence.
+// ok is false when there's no more k-mers or an error occurred, see Err().
+func (iter *KmerIterator) Next() (code uint64, pos int, ok bool) {
+	var v uint64
+	for iter.i < len(iter.seq) {
+		v = iter.table[iter.seq[iter.i]]
+		iter.i++
+
+		if v == 4 {
+			if iter.policy == Strict {
+				iter.err = ErrIllegalBase
+				iter.i = len(iter.seq)
+				break
+			}
+			// restart from the next base
+			iter.n = 0
+			iter.code = 0
+			continue
+		}
+
+		iter.code = (iter.code<<2 | v) & iter.mask
+		if iter.n < iter.k {
+			iter.n++
+		}
+		if iter.n == iter.k {
+			return iter.code, iter.i - iter.k, true
+		}
+	}
+	return 0, 0, false
+}
+
+// CanonicalKmerIterator iterates canonical k-mers of a sequence.
+// Codes of the forward k-mer and its reverse complement are updated together,
+// in the way of MustEncodeFromFormerKmer and MustEncodeFromLatterKmer respectively,
+// so Canonical() is not needed for every k-mer.
+// Illegal bases are handled in the same way as KmerIterator.
+type CanonicalKmerIterator struct {
+	seq    []byte
+	k      int
+	mask   uint64
+	shift  uint // bits of k-1 bases
+	policy EncodePolicy
+	table  *[256]uint64 // encoding table of the policy
+
+	i      int    // index of the next base to read
+	n      int    // number of consecutive legal bases, up to k
+	code   uint64 // code of the last n bases
+	rcCode uint64 // code of the reverse complement of the last n bases
+	err    error
+}
+
+// NewCanonicalKmerIterator returns a CanonicalKmerIterator for the sequence.
+func NewCanonicalKmerIterator(seq []byte, k int) (*CanonicalKmerIterator, error) {
+	if k <= 0 || k > 32 {
+		return nil, ErrKOverflow
+	}
+	return &CanonicalKmerIterator{
+		seq:   seq,
+		k:     k,
+		mask:  (1 << uint(k<<1)) - 1,
+		shift: uint(k-1) << 1,
+		table: &base2bit,
+	}, nil
+}
+
+// SetPolicy sets the policy for handling degenerate bases.
+func (iter *CanonicalKmerIterator) SetPolicy(policy EncodePolicy) {
+	iter.policy = policy
+	iter.table = policy.table()
+}
+
+// Reset resets the iterator with a new sequence, so it can be reused.
+func (iter *CanonicalKmerIterator) Reset(seq []byte) {
+	iter.seq = seq
+	iter.i = 0
+	iter.n = 0
+	iter.code = 0
+	iter.rcCode = 0
+	iter.err = nil
+}
+
+// K returns the k-mer size.
+func (iter *CanonicalKmerIterator) K() int {
+	return iter.k
+}
+
+// Err returns the error that stopped the iteration,
+// i.e., ErrIllegalBase in the Strict encode policy.
+func (iter *CanonicalKmerIterator) Err() error {
+	return iter.err
+}
+
+// Next returns the canonical code of the next k-mer and its position (0-based) in the sequence.
+// rc is true if the canonical k-mer is the reverse complement of the k-mer in the sequence.
+// For palindromic k-mers, rc is false.
+// ok is false when there's no more k-mers or an error occurred, see Err().
+func (iter *CanonicalKmerIterator) Next() (code uint64, pos int, rc bool, ok bool) {
+	var rcCode uint64
+	code, rcCode, pos, ok = iter.next()
+	if rcCode < code {
+		return rcCode, pos, true, ok
+	}
+	return code, pos, false, ok
+}
+
+// next returns codes of the next k-mer and its reverse complement.
+func (iter *CanonicalKmerIterator) next() (code uint64, rcCode uint64, pos int, ok bool) {
+	var v uint64
+	for iter.i < len(iter.seq) {
+		v = iter.table[iter.seq[iter.i]]
+		iter.i++
+
+		if v == 4 {
+			if iter.policy == Strict {
+				iter.err = ErrIllegalBase
+				iter.i = len(iter.seq)
+				break
+			}
+			// restart from the next base
+			iter.n = 0
+			iter.code = 0
+			iter.rcCode = 0
+			continue
+		}
+
+		iter.code = (iter.code<<2 | v) & iter.mask
+		iter.rcCode = iter.rcCode>>2 | (v^3)<<iter.shift
+		if iter.n < iter.k {
+			iter.n++
+		}
+		if iter.n == iter.k {
+			return iter.code, iter.rcCode, iter.i - iter.k, true
+		}
+	}
+	return 0, 0, 0, false
+}
@@ -0,0 +1,123 @@
+// Copyright © 2018-2021 Wei Shen <shenwei356@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package kmers
+
+import (
+	"testing"
+)
+
+func TestKmerIterator(t *testing.T) {
+	seq := []byte("ACGTNacgtX-CCGTAAGT-AC")
+
+	for _, k := range []int{1, 2, 3, 5, 8, 32} {
+		iter, err := NewKmerIterator(seq, k)
+		if err != nil {
+			t.Errorf("NewKmerIterator error: %s", err)
+			continue
+		}
+
+		// expected k-mers
+		codes := make([]uint64, 0, len(seq))
+		poss := make([]int, 0, len(seq))
+		for i := 0; i+k <= len(seq); i++ {
+			code, err := Encode(seq[i : i+k])
+			if err != nil {
+				continue
+			}
+			codes = append(codes, code)
+			poss = append(poss, i)
+		}
+
+		var j int
+		for {
+			code, pos, ok := iter.Next()
+			if !ok {
+				break
+			}
+			if j >= len(codes) {
+				t.Errorf("k=%d: unexpected k-mer at %d", k, pos)
+				break
+			}
+			if code != codes[j] || pos != poss[j] {
+				t.Errorf("k=%d: expected %d at %d, returned %d at %d", k, codes[j], poss[j], code, pos)
+			}
+			j++
+		}
+		if j != len(codes) {
+			t.Errorf("k=%d: expected %d k-mers, returned %d", k, len(codes), j)
+		}
+	}
+
+	if _, err := NewKmerIterator(seq, 33); err != ErrKOverflow {
+		t.Errorf("NewKmerIterator should fail for k=33")
+	}
+}
+
+func TestCanonicalKmerIterator(t *testing.T) {
+	seq := []byte("ACGTNacgtX-CCGTAAGT-ACGGATTTAAACCCGGGATCGAATGC")
+
+	for _, k := range []int{1, 2, 3, 4, 7, 31, 32} {
+		iter, err := NewCanonicalKmerIterator(seq, k)
+		if err != nil {
+			t.Errorf("NewCanonicalKmerIterator error: %s", err)
+			continue
+		}
+		iter0, _ := NewKmerIterator(seq, k)
+
+		for {
+			code0, pos0, ok0 := iter0.Next()
+			code, pos, rc, ok := iter.Next()
+			if ok != ok0 {
+				t.Errorf("k=%d: unexpected end of iteration", k)
+				break
+			}
+			if !ok {
+				break
+			}
+
+			if pos != pos0 {
+				t.Errorf("k=%d: expected position %d, returned %d", k, pos0, pos)
+			}
+			if code != Canonical(code0, k) {
+				t.Errorf("k=%d: expected %d at %d, returned %d", k, Canonical(code0, k), pos, code)
+			}
+			if rc != (code != code0) {
+				t.Errorf("k=%d: wrong strand at %d", k, pos)
+			}
+		}
+	}
+}
+
+func BenchmarkCanonicalKmerIteratorK31(b *testing.B) {
+	iter, _ := NewCanonicalKmerIterator(benchMer, 31)
+	var code uint64
+	var ok bool
+	for i := 0; i < b.N; i++ {
+		iter.Reset(benchMer)
+		for {
+			code, _, _, ok = iter.Next()
+			if !ok {
+				break
+			}
+			result = code
+		}
+	}
+}
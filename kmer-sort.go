@@ -60,3 +60,75 @@ func (codes CodeSlice) Swap(i, j int) {
 func (codes CodeSlice) Less(i, j int) bool {
 	return codes[i] < codes[j]
 }
+
+// KmerCode128Slice is a slice of KmerCode128, for sorting
+type KmerCode128Slice []KmerCode128
+
+// Len return length of the slice
+func (codes KmerCode128Slice) Len() int {
+	return len(codes)
+}
+
+// Swap swaps two elements
+func (codes KmerCode128Slice) Swap(i, j int) {
+	codes[i], codes[j] = codes[j], codes[i]
+}
+
+// Less simply compare two KmerCode128
+func (codes KmerCode128Slice) Less(i, j int) bool {
+	return codes[i].Code.Less(codes[j].Code)
+}
+
+// Code128Slice is a slice of Code128, for sorting
+type Code128Slice []Code128
+
+// Len return length of the slice
+func (codes Code128Slice) Len() int {
+	return len(codes)
+}
+
+// Swap swaps two elements
+func (codes Code128Slice) Swap(i, j int) {
+	codes[i], codes[j] = codes[j], codes[i]
+}
+
+// Less simply compare two Code128
+func (codes Code128Slice) Less(i, j int) bool {
+	return codes[i].Less(codes[j])
+}
+
+// LongKmerSlice is a slice of LongKmer, for sorting.
+type LongKmerSlice []LongKmer
+
+// Len return length of the slice
+func (kmers LongKmerSlice) Len() int {
+	return len(kmers)
+}
+
+// Swap swaps two elements
+func (kmers LongKmerSlice) Swap(i, j int) {
+	kmers[i], kmers[j] = kmers[j], kmers[i]
+}
+
+// Less simply compare two LongKmers
+func (kmers LongKmerSlice) Less(i, j int) bool {
+	return kmers[i].Less(kmers[j])
+}
+
+// ProteinKmerCodeSlice is a slice of ProteinKmerCode, for sorting
+type ProteinKmerCodeSlice []ProteinKmerCode
+
+// Len return length of the slice
+func (codes ProteinKmerCodeSlice) Len() int {
+	return len(codes)
+}
+
+// Swap swaps two elements
+func (codes ProteinKmerCodeSlice) Swap(i, j int) {
+	codes[i], codes[j] = codes[j], codes[i]
+}
+
+// Less simply compare two ProteinKmerCode
+func (codes ProteinKmerCodeSlice) Less(i, j int) bool {
+	return codes[i].Code < codes[j].Code
+}
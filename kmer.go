@@ -66,13 +66,50 @@ var base2bit = [256]uint64{
 
 // var base2bit []uint64
 
+// base2bitStrict is base2bit with degenerate bases and U treated as illegal bases.
+var base2bitStrict [256]uint64
+
+// degenerateBases are IUPAC symbols for more than one base.
+var degenerateBases = []byte("MVHRDWSBYKNmvhrdwsbykn")
+
+func init() {
+	base2bitStrict = base2bit
+	for _, b := range degenerateBases {
+		base2bitStrict[b] = 4
+	}
+	base2bitStrict['U'] = 4
+	base2bitStrict['u'] = 4
+}
+
+// EncodePolicy decides how degenerate bases are handled in encoding.
+type EncodePolicy int
+
+const (
+	// Lenient keeps the first base of degenerate bases, which is the default policy.
+	Lenient EncodePolicy = iota
+	// Strict treats all bases except A, C, G and T as illegal bases,
+	// and iterators stop with ErrIllegalBase when meeting them.
+	Strict
+	// Skip treats all bases except A, C, G and T as illegal bases,
+	// and iterators skip them and restart from the next base.
+	Skip
+)
+
+// table returns the encoding table of the policy.
+func (policy EncodePolicy) table() *[256]uint64 {
+	if policy == Lenient {
+		return &base2bit
+	}
+	return &base2bitStrict
+}
+
 // MaxCode is the maxinum interger for all Ks.
 var MaxCode []uint64
 
 func init() {
 	MaxCode = make([]uint64, 33)
 	for i := 1; i <= 32; i++ {
-		MaxCode[i] = (1 << uint(1<<i)) - 1 // (1<<(i*2)) - 1
+		MaxCode[i] = (1 << uint(i<<1)) - 1
 	}
 }
 
@@ -98,6 +135,9 @@ func init() {
 //	Y       CT     C
 //	K       GT     G
 //	N       ACGT   A
+//
+// It's the default Lenient policy, degenerate bases can also be
+// treated as illegal bases with EncodeWithPolicy.
 func Encode(kmer []byte) (code uint64, err error) {
 	if len(kmer) == 0 || len(kmer) > 32 {
 		return 0, ErrKOverflow
@@ -116,6 +156,26 @@ func Encode(kmer []byte) (code uint64, err error) {
 	return code, nil
 }
 
+// EncodeWithPolicy is similar to Encode, but handles degenerate bases with the given policy.
+// For a single k-mer, Skip is the same as Strict, i.e., ErrIllegalBase is returned.
+func EncodeWithPolicy(kmer []byte, policy EncodePolicy) (code uint64, err error) {
+	if len(kmer) == 0 || len(kmer) > 32 {
+		return 0, ErrKOverflow
+	}
+
+	table := policy.table()
+	var v uint64
+	for _, b := range kmer {
+		code <<= 2
+		v = table[b]
+		if v == 4 {
+			return code, ErrIllegalBase
+		}
+		code |= v
+	}
+	return code, nil
+}
+
 // ErrNotConsecutiveKmers means the two k-mers are not consecutive.
 var ErrNotConsecutiveKmers = errors.New("kmers: not consecutive k-mers")
 
@@ -133,16 +193,43 @@ func MustEncodeFromFormerKmer(kmer []byte, leftKmer []byte, leftCode uint64) (ui
 
 // EncodeFromFormerKmer encodes from the former k-mer, inspired by ntHash
 func EncodeFromFormerKmer(kmer []byte, leftKmer []byte, leftCode uint64) (uint64, error) {
+	if err := checkFormerKmer(kmer, leftKmer); err != nil {
+		return 0, err
+	}
+	return MustEncodeFromFormerKmer(kmer, leftKmer, leftCode)
+}
+
+// checkFormerKmer checks if leftKmer is the former k-mer of the k-mer.
+func checkFormerKmer(kmer []byte, leftKmer []byte) error {
 	if len(kmer) == 0 {
-		return 0, ErrKOverflow
+		return ErrKOverflow
 	}
 	if len(kmer) != len(leftKmer) {
-		return 0, ErrKMismatch
+		return ErrKMismatch
 	}
 	if !bytes.Equal(kmer[0:len(kmer)-1], leftKmer[1:]) {
-		return 0, ErrNotConsecutiveKmers
+		return ErrNotConsecutiveKmers
 	}
-	return MustEncodeFromFormerKmer(kmer, leftKmer, leftCode)
+	return nil
+}
+
+// MustEncodeFromFormerKmerWithPolicy is similar to MustEncodeFromFormerKmer,
+// but handles degenerate bases with the given policy.
+func MustEncodeFromFormerKmerWithPolicy(kmer []byte, leftKmer []byte, leftCode uint64, policy EncodePolicy) (uint64, error) {
+	v := policy.table()[kmer[len(kmer)-1]]
+	if v == 4 {
+		return leftCode, ErrIllegalBase
+	}
+	return (leftCode&((1<<(uint(len(kmer)-1)<<1))-1))<<2 | v, nil
+}
+
+// EncodeFromFormerKmerWithPolicy is similar to EncodeFromFormerKmer,
+// but handles degenerate bases with the given policy.
+func EncodeFromFormerKmerWithPolicy(kmer []byte, leftKmer []byte, leftCode uint64, policy EncodePolicy) (uint64, error) {
+	if err := checkFormerKmer(kmer, leftKmer); err != nil {
+		return 0, err
+	}
+	return MustEncodeFromFormerKmerWithPolicy(kmer, leftKmer, leftCode, policy)
 }
 
 // MustEncodeFromLatterKmer encodes from the latter k-mer,
@@ -159,16 +246,43 @@ func MustEncodeFromLatterKmer(kmer []byte, rightKmer []byte, rightCode uint64) (
 
 // EncodeFromLatterKmer encodes from the former k-mer.
 func EncodeFromLatterKmer(kmer []byte, rightKmer []byte, rightCode uint64) (uint64, error) {
+	if err := checkLatterKmer(kmer, rightKmer); err != nil {
+		return 0, err
+	}
+	return MustEncodeFromLatterKmer(kmer, rightKmer, rightCode)
+}
+
+// checkLatterKmer checks if rightKmer is the latter k-mer of the k-mer.
+func checkLatterKmer(kmer []byte, rightKmer []byte) error {
 	if len(kmer) == 0 {
-		return 0, ErrKOverflow
+		return ErrKOverflow
 	}
 	if len(kmer) != len(rightKmer) {
-		return 0, ErrKMismatch
+		return ErrKMismatch
 	}
 	if !bytes.Equal(rightKmer[0:len(kmer)-1], kmer[1:len(rightKmer)]) {
-		return 0, ErrNotConsecutiveKmers
+		return ErrNotConsecutiveKmers
 	}
-	return MustEncodeFromLatterKmer(kmer, rightKmer, rightCode)
+	return nil
+}
+
+// MustEncodeFromLatterKmerWithPolicy is similar to MustEncodeFromLatterKmer,
+// but handles degenerate bases with the given policy.
+func MustEncodeFromLatterKmerWithPolicy(kmer []byte, rightKmer []byte, rightCode uint64, policy EncodePolicy) (uint64, error) {
+	v := policy.table()[kmer[0]]
+	if v == 4 {
+		return rightCode, ErrIllegalBase
+	}
+	return v<<(uint(len(kmer)-1)<<1) | rightCode>>2, nil
+}
+
+// EncodeFromLatterKmerWithPolicy is similar to EncodeFromLatterKmer,
+// but handles degenerate bases with the given policy.
+func EncodeFromLatterKmerWithPolicy(kmer []byte, rightKmer []byte, rightCode uint64, policy EncodePolicy) (uint64, error) {
+	if err := checkLatterKmer(kmer, rightKmer); err != nil {
+		return 0, err
+	}
+	return MustEncodeFromLatterKmerWithPolicy(kmer, rightKmer, rightCode, policy)
 }
 
 // Reverse returns code of the reversed sequence.
@@ -359,6 +473,34 @@ func MustDecode(code uint64, k int) []byte {
 	return kmer
 }
 
+// AppendDecode appends the decoded k-mer to dst and returns the extended buffer,
+// it's the allocation-free version of Decode if dst has enough capacity.
+func AppendDecode(dst []byte, code uint64, k int) []byte {
+	if k <= 0 || k > 32 {
+		panic(ErrKOverflow)
+	}
+	if code > MaxCode[k] {
+		panic(ErrCodeOverflow)
+	}
+	return MustAppendDecode(dst, code, k)
+}
+
+// MustAppendDecode is similar to AppendDecode, but does not check k and code.
+func MustAppendDecode(dst []byte, code uint64, k int) []byte {
+	n := len(dst)
+	if cap(dst)-n < k {
+		dst2 := make([]byte, n, (n+k)<<1)
+		copy(dst2, dst)
+		dst = dst2
+	}
+	dst = dst[:n+k]
+	for i := n + k - 1; i >= n; i-- {
+		dst[i] = bit2base[code&3]
+		code >>= 2
+	}
+	return dst
+}
+
 // BaseAt returns the base in pos i (0-based).
 func BaseAt(code uint64, k int, i int) uint8 {
 	if i < 0 || i >= k {
@@ -451,3 +593,265 @@ func MustHasPrefix(code uint64, prefix uint64, k1, k2 int) bool {
 	}
 	return code>>((k1-k2)<<1) == prefix
 }
+
+// HasSuffix check if a k-mer has a suffix
+func HasSuffix(code uint64, suffix uint64, k1, k2 int) bool {
+	if k1 <= 0 || k1 > 32 || k2 <= 0 || k2 > 32 {
+		panic(ErrKOverflow)
+	}
+
+	if k1 < k2 {
+		return false
+	}
+	return code&((1<<uint(k2<<1))-1) == suffix
+}
+
+// MustHasSuffix check if a k-mer has a suffix
+func MustHasSuffix(code uint64, suffix uint64, k1, k2 int) bool {
+	if k1 < k2 {
+		return false
+	}
+	return code&((1<<uint(k2<<1))-1) == suffix
+}
+
+// LongestSuffix returns the length of the longest common suffix.
+func LongestSuffix(code1, code2 uint64, k1, k2 int) int {
+	if k1 <= 0 || k1 > 32 || k2 <= 0 || k2 > 32 {
+		panic(ErrKOverflow)
+	}
+
+	n := bits.TrailingZeros64(code1^code2) >> 1
+	if k1 < n {
+		n = k1
+	}
+	if k2 < n {
+		n = k2
+	}
+	return n
+}
+
+// MustLongestSuffix returns the length of the longest common suffix.
+func MustLongestSuffix(code1, code2 uint64, k1, k2 int) int {
+	n := bits.TrailingZeros64(code1^code2) >> 1
+	if k1 < n {
+		n = k1
+	}
+	if k2 < n {
+		n = k2
+	}
+	return n
+}
+
+// Substr returns the substring of n bases starting from position i (0-based).
+func Substr(code uint64, k int, i int, n int) uint64 {
+	if i < 0 || i >= k {
+		panic(ErrPositionOverflow)
+	}
+	if n < 1 || i+n > k {
+		panic(ErrLengthOverflow)
+	}
+	return code >> uint((k-i-n)<<1) & ((1 << uint(n<<1)) - 1)
+}
+
+// MustSubstr returns the substring of n bases starting from position i (0-based).
+func MustSubstr(code uint64, k int, i int, n int) uint64 {
+	return code >> uint((k-i-n)<<1) & ((1 << uint(n<<1)) - 1)
+}
+
+// Concat returns the code of the concatenation of two k-mers, k1+k2 needs to be <= 32.
+func Concat(code1, code2 uint64, k1, k2 int) uint64 {
+	if k1 <= 0 || k2 <= 0 || k1+k2 > 32 {
+		panic(ErrKOverflow)
+	}
+	return code1<<uint(k2<<1) | code2
+}
+
+// MustConcat returns the code of the concatenation of two k-mers, k1+k2 needs to be <= 32.
+func MustConcat(code1, code2 uint64, k1, k2 int) uint64 {
+	return code1<<uint(k2<<1) | code2
+}
+
+// Overlap returns the length of the longest suffix of code1
+// which is also a prefix of code2, 0 for no overlap.
+func Overlap(code1, code2 uint64, k1, k2 int) int {
+	if k1 <= 0 || k1 > 32 || k2 <= 0 || k2 > 32 {
+		panic(ErrKOverflow)
+	}
+	return MustOverlap(code1, code2, k1, k2)
+}
+
+// MustOverlap returns the length of the longest suffix of code1
+// which is also a prefix of code2, 0 for no overlap.
+func MustOverlap(code1, code2 uint64, k1, k2 int) int {
+	n := k1
+	if k2 < n {
+		n = k2
+	}
+	for ; n > 0; n-- {
+		if code1&((1<<uint(n<<1))-1) == code2>>uint((k2-n)<<1) {
+			return n
+		}
+	}
+	return 0
+}
+
+// HammingDistance returns the number of mismatched bases of two k-mers,
+// by counting non-zero 2-bit lanes of code1^code2.
+func HammingDistance(code1, code2 uint64, k int) int {
+	if k <= 0 || k > 32 {
+		panic(ErrKOverflow)
+	}
+	x := code1 ^ code2
+	return bits.OnesCount64((x | x>>1) & 0x5555555555555555)
+}
+
+// MustHammingDistance is similar to HammingDistance, but does not check k.
+func MustHammingDistance(code1, code2 uint64, k int) int {
+	x := code1 ^ code2
+	return bits.OnesCount64((x | x>>1) & 0x5555555555555555)
+}
+
+// MismatchPositions returns positions (0-based) of mismatched bases of two k-mers.
+func MismatchPositions(code1, code2 uint64, k int) []int {
+	if k <= 0 || k > 32 {
+		panic(ErrKOverflow)
+	}
+	return AppendMismatchPositions(nil, code1, code2, k)
+}
+
+// AppendMismatchPositions appends positions (0-based) of mismatched bases
+// of two k-mers to dst and returns the extended buffer.
+func AppendMismatchPositions(dst []int, code1, code2 uint64, k int) []int {
+	x := code1 ^ code2
+	x = (x | x>>1) & 0x5555555555555555
+	d := 32 - k
+	var lz int
+	for x > 0 {
+		lz = bits.LeadingZeros64(x)
+		dst = append(dst, lz>>1-d)
+		x &^= 1 << uint(63-lz)
+	}
+	return dst
+}
+
+// AppendHammingDistances computes Hamming distances between a query k-mer and a list of k-mers,
+// appends them to dst and returns the extended buffer.
+func AppendHammingDistances(dst []int, query uint64, codes CodeSlice, k int) []int {
+	if k <= 0 || k > 32 {
+		panic(ErrKOverflow)
+	}
+	var x uint64
+	for _, code := range codes {
+		x = query ^ code
+		dst = append(dst, bits.OnesCount64((x|x>>1)&0x5555555555555555))
+	}
+	return dst
+}
+
+// HammingSearch returns indexes of k-mers within d mismatches of a query k-mer,
+// e.g., for matching a barcode against a whitelist.
+func HammingSearch(query uint64, codes CodeSlice, k int, d int) []int {
+	if k <= 0 || k > 32 {
+		panic(ErrKOverflow)
+	}
+	var idx []int
+	var x uint64
+	for i, code := range codes {
+		x = query ^ code
+		if bits.OnesCount64((x|x>>1)&0x5555555555555555) <= d {
+			idx = append(idx, i)
+		}
+	}
+	return idx
+}
+
+// Successors returns codes of the 4 possible next k-mers,
+// i.e., the last k-1 bases followed by A, C, G and T respectively.
+func Successors(code uint64, k int) [4]uint64 {
+	if k <= 0 || k > 32 {
+		panic(ErrKOverflow)
+	}
+	return MustSuccessors(code, k)
+}
+
+// MustSuccessors is similar to Successors, but does not check k.
+func MustSuccessors(code uint64, k int) [4]uint64 {
+	c := (code & ((1 << (uint(k-1) << 1)) - 1)) << 2
+	return [4]uint64{c, c | 1, c | 2, c | 3}
+}
+
+// Predecessors returns codes of the 4 possible previous k-mers,
+// i.e., A, C, G and T respectively followed by the first k-1 bases.
+func Predecessors(code uint64, k int) [4]uint64 {
+	if k <= 0 || k > 32 {
+		panic(ErrKOverflow)
+	}
+	return MustPredecessors(code, k)
+}
+
+// MustPredecessors is similar to Predecessors, but does not check k.
+func MustPredecessors(code uint64, k int) [4]uint64 {
+	c := code >> 2
+	shift := uint(k-1) << 1
+	return [4]uint64{c, 1<<shift | c, 2<<shift | c, 3<<shift | c}
+}
+
+// CanonicalSuccessors returns canonical codes of the 4 possible next k-mers of a k-mer,
+// in the order of the appended base A, C, G and T.
+// rcs[i] is true if the canonical k-mer is the reverse complement of the next k-mer,
+// which means the next k-mer is visited on the reverse strand of the canonical one.
+// To step forward from a canonical k-mer on its reverse strand, pass RevComp(code, k).
+func CanonicalSuccessors(code uint64, k int) (codes [4]uint64, rcs [4]bool) {
+	if k <= 0 || k > 32 {
+		panic(ErrKOverflow)
+	}
+	return MustCanonicalSuccessors(code, k)
+}
+
+// MustCanonicalSuccessors is similar to CanonicalSuccessors, but does not check k.
+func MustCanonicalSuccessors(code uint64, k int) (codes [4]uint64, rcs [4]bool) {
+	rc := MustRevComp(code, k)
+	c := (code & ((1 << (uint(k-1) << 1)) - 1)) << 2
+	rc >>= 2
+	shift := uint(k-1) << 1
+	var fwd, rev uint64
+	for b := uint64(0); b < 4; b++ {
+		fwd = c | b
+		rev = (b^3)<<shift | rc
+		if rev < fwd {
+			codes[b], rcs[b] = rev, true
+		} else {
+			codes[b] = fwd
+		}
+	}
+	return
+}
+
+// CanonicalPredecessors returns canonical codes of the 4 possible previous k-mers of a k-mer,
+// in the order of the prepended base A, C, G and T.
+// rcs[i] is true if the canonical k-mer is the reverse complement of the previous k-mer.
+func CanonicalPredecessors(code uint64, k int) (codes [4]uint64, rcs [4]bool) {
+	if k <= 0 || k > 32 {
+		panic(ErrKOverflow)
+	}
+	return MustCanonicalPredecessors(code, k)
+}
+
+// MustCanonicalPredecessors is similar to CanonicalPredecessors, but does not check k.
+func MustCanonicalPredecessors(code uint64, k int) (codes [4]uint64, rcs [4]bool) {
+	rc := MustRevComp(code, k)
+	c := code >> 2
+	shift := uint(k-1) << 1
+	rc = (rc & ((1 << shift) - 1)) << 2
+	var fwd, rev uint64
+	for b := uint64(0); b < 4; b++ {
+		fwd = b<<shift | c
+		rev = rc | (b ^ 3)
+		if rev < fwd {
+			codes[b], rcs[b] = rev, true
+		} else {
+			codes[b] = fwd
+		}
+	}
+	return
+}
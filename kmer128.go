@@ -0,0 +1,321 @@
+// Copyright © 2018-2021 Wei Shen <shenwei356@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package kmers
+
+import (
+	"bytes"
+	"errors"
+	"math/bits"
+)
+
+// ErrKOverflow128 means K > 64.
+var ErrKOverflow128 = errors.New("kmers: k-mer size (1-64) overflow")
+
+// Code128 is a 128-bit k-mer code for k<=64, with the higher 64 bits in Hi,
+// and the lower 64 bits in Lo. Bases are encoded in the same way as Encode.
+type Code128 struct {
+	Hi uint64
+	Lo uint64
+}
+
+// Less compares two codes.
+func (c Code128) Less(c2 Code128) bool {
+	return c.Hi < c2.Hi || (c.Hi == c2.Hi && c.Lo < c2.Lo)
+}
+
+// shl shifts the code left by n bits.
+func (c Code128) shl(n uint) Code128 {
+	if n >= 64 {
+		return Code128{c.Lo << (n - 64), 0}
+	}
+	return Code128{c.Hi<<n | c.Lo>>(64-n), c.Lo << n}
+}
+
+// shr shifts the code right by n bits.
+func (c Code128) shr(n uint) Code128 {
+	if n >= 64 {
+		return Code128{0, c.Hi >> (n - 64)}
+	}
+	return Code128{c.Hi >> n, c.Lo>>n | c.Hi<<(64-n)}
+}
+
+// mask128 returns a mask of lower k*2 bits.
+func mask128(k int) Code128 {
+	if k > 32 {
+		return Code128{(1 << uint((k-32)<<1)) - 1, 1<<64 - 1}
+	}
+	return Code128{0, (1 << uint(k<<1)) - 1}
+}
+
+// Encode128 converts byte slice to bits, for k<=64.
+// Degenerate bases are handled in the same way as Encode.
+func Encode128(kmer []byte) (code Code128, err error) {
+	if len(kmer) == 0 || len(kmer) > 64 {
+		return code, ErrKOverflow128
+	}
+
+	var v uint64
+	for _, b := range kmer {
+		v = base2bit[b]
+		if v == 4 {
+			return code, ErrIllegalBase
+		}
+		code.Hi = code.Hi<<2 | code.Lo>>62
+		code.Lo = code.Lo<<2 | v
+	}
+	return code, nil
+}
+
+// MustEncodeFromFormerKmer128 encodes from former the k-mer,
+// assuming the k-mer and leftKmer are both OK.
+func MustEncodeFromFormerKmer128(kmer []byte, leftKmer []byte, leftCode Code128) (Code128, error) {
+	v := base2bit[kmer[len(kmer)-1]]
+	if v == 4 {
+		return leftCode, ErrIllegalBase
+	}
+	// retrieve lower (k-1)*2 bits and << 2, and then add v
+	m := mask128(len(kmer) - 1)
+	return Code128{
+		(leftCode.Hi&m.Hi)<<2 | (leftCode.Lo&m.Lo)>>62,
+		(leftCode.Lo&m.Lo)<<2 | v,
+	}, nil
+}
+
+// EncodeFromFormerKmer128 encodes from the former k-mer.
+func EncodeFromFormerKmer128(kmer []byte, leftKmer []byte, leftCode Code128) (Code128, error) {
+	if len(kmer) == 0 || len(kmer) > 64 {
+		return Code128{}, ErrKOverflow128
+	}
+	if len(kmer) != len(leftKmer) {
+		return Code128{}, ErrKMismatch
+	}
+	if !bytes.Equal(kmer[0:len(kmer)-1], leftKmer[1:]) {
+		return Code128{}, ErrNotConsecutiveKmers
+	}
+	return MustEncodeFromFormerKmer128(kmer, leftKmer, leftCode)
+}
+
+// MustEncodeFromLatterKmer128 encodes from the latter k-mer,
+// assuming the k-mer and rightKmer are both OK.
+func MustEncodeFromLatterKmer128(kmer []byte, rightKmer []byte, rightCode Code128) (Code128, error) {
+	v := base2bit[kmer[0]]
+	if v == 4 {
+		return rightCode, ErrIllegalBase
+	}
+
+	code := rightCode.shr(2)
+	k := len(kmer)
+	if k > 32 {
+		code.Hi |= v << (uint(k-33) << 1)
+	} else {
+		code.Lo |= v << (uint(k-1) << 1)
+	}
+	return code, nil
+}
+
+// EncodeFromLatterKmer128 encodes from the latter k-mer.
+func EncodeFromLatterKmer128(kmer []byte, rightKmer []byte, rightCode Code128) (Code128, error) {
+	if len(kmer) == 0 || len(kmer) > 64 {
+		return Code128{}, ErrKOverflow128
+	}
+	if len(kmer) != len(rightKmer) {
+		return Code128{}, ErrKMismatch
+	}
+	if !bytes.Equal(rightKmer[0:len(kmer)-1], kmer[1:len(rightKmer)]) {
+		return Code128{}, ErrNotConsecutiveKmers
+	}
+	return MustEncodeFromLatterKmer128(kmer, rightKmer, rightCode)
+}
+
+// Reverse128 returns code of the reversed sequence.
+func Reverse128(code Code128, k int) Code128 {
+	if k <= 0 || k > 64 {
+		panic(ErrKOverflow128)
+	}
+	return MustReverse128(code, k)
+}
+
+// MustReverse128 is similar to Reverse128, but does not check k.
+func MustReverse128(code Code128, k int) Code128 {
+	// reverse the two words respectively, and swap them.
+	c := Code128{MustReverse(code.Lo, 32), MustReverse(code.Hi, 32)}
+	return c.shr(uint(64-k) << 1)
+}
+
+// Complement128 returns code of complement sequence.
+func Complement128(code Code128, k int) Code128 {
+	if k <= 0 || k > 64 {
+		panic(ErrKOverflow128)
+	}
+	return MustComplement128(code, k)
+}
+
+// MustComplement128 is similar to Complement128, but does not check k.
+func MustComplement128(code Code128, k int) Code128 {
+	m := mask128(k)
+	return Code128{code.Hi ^ m.Hi, code.Lo ^ m.Lo}
+}
+
+// RevComp128 returns code of reverse complement sequence.
+func RevComp128(code Code128, k int) Code128 {
+	if k <= 0 || k > 64 {
+		panic(ErrKOverflow128)
+	}
+	return MustRevComp128(code, k)
+}
+
+// MustRevComp128 is similar to RevComp128, but does not check k.
+func MustRevComp128(code Code128, k int) Code128 {
+	c := Code128{MustRevComp(code.Lo, 32), MustRevComp(code.Hi, 32)}
+	return c.shr(uint(64-k) << 1)
+}
+
+// Canonical128 returns code of its canonical kmer.
+func Canonical128(code Code128, k int) Code128 {
+	if k <= 0 || k > 64 {
+		panic(ErrKOverflow128)
+	}
+	return MustCanonical128(code, k)
+}
+
+// MustCanonical128 is similar to Canonical128, but does not check k.
+func MustCanonical128(code Code128, k int) Code128 {
+	rc := MustRevComp128(code, k)
+	if rc.Less(code) {
+		return rc
+	}
+	return code
+}
+
+// Decode128 converts the code to original seq.
+func Decode128(code Code128, k int) []byte {
+	if k <= 0 || k > 64 {
+		panic(ErrKOverflow128)
+	}
+	m := mask128(k)
+	if code.Hi&^m.Hi != 0 || code.Lo&^m.Lo != 0 {
+		panic(ErrCodeOverflow)
+	}
+	return MustDecode128(code, k)
+}
+
+// MustDecode128 is similar to Decode128, but does not check k and code.
+func MustDecode128(code Code128, k int) []byte {
+	kmer := make([]byte, k)
+	c := code.Lo
+	for i := 0; i < k; i++ {
+		if i == 32 {
+			c = code.Hi
+		}
+		kmer[k-1-i] = bit2base[c&3]
+		c >>= 2
+	}
+	return kmer
+}
+
+// BaseAt128 returns the base in pos i (0-based).
+func BaseAt128(code Code128, k int, i int) uint8 {
+	if i < 0 || i >= k {
+		panic(ErrPositionOverflow)
+	}
+	return MustBaseAt128(code, k, i)
+}
+
+// MustBaseAt128 returns the base in pos i (0-based).
+func MustBaseAt128(code Code128, k int, i int) uint8 {
+	j := k - i - 1
+	if j >= 32 {
+		return uint8(code.Hi >> ((j - 32) << 1) & 3)
+	}
+	return uint8(code.Lo >> (j << 1) & 3)
+}
+
+// Prefix128 returns the first n bases. n needs to be > 0.
+// The length of the prefix is n.
+func Prefix128(code Code128, k int, n int) Code128 {
+	if n < 1 || n > k {
+		panic(ErrLengthOverflow)
+	}
+	return code.shr(uint(k-n) << 1)
+}
+
+// MustPrefix128 returns the first n bases. n needs to be > 0.
+// The length of the prefix is n.
+func MustPrefix128(code Code128, k int, n int) Code128 {
+	return code.shr(uint(k-n) << 1)
+}
+
+// Suffix128 returns the suffix starting from position i (0-based).
+// The length of the suffix is k - i.
+func Suffix128(code Code128, k int, i int) Code128 {
+	if i < 0 || i >= k {
+		panic(ErrPositionOverflow)
+	}
+	return MustSuffix128(code, k, i)
+}
+
+// MustSuffix128 returns the suffix starting from position i (0-based).
+// The length of the suffix is k - i.
+func MustSuffix128(code Code128, k int, i int) Code128 {
+	m := mask128(k - i)
+	return Code128{code.Hi & m.Hi, code.Lo & m.Lo}
+}
+
+// LongestPrefix128 returns the length of the longest prefix.
+func LongestPrefix128(code1, code2 Code128, k1, k2 int) int {
+	if k1 <= 0 || k1 > 64 || k2 <= 0 || k2 > 64 {
+		panic(ErrKOverflow128)
+	}
+	return MustLongestPrefix128(code1, code2, k1, k2)
+}
+
+// MustLongestPrefix128 returns the length of the longest prefix.
+func MustLongestPrefix128(code1, code2 Code128, k1, k2 int) int {
+	var d int
+	if k1 >= k2 { // most of the cases
+		code1 = code1.shr(uint(k1-k2) << 1)
+		d = 64 - k2
+	} else {
+		code2 = code2.shr(uint(k2-k1) << 1)
+		d = 64 - k1
+	}
+	hi, lo := code1.Hi^code2.Hi, code1.Lo^code2.Lo
+	if hi != 0 {
+		return bits.LeadingZeros64(hi)>>1 - d
+	}
+	return (64+bits.LeadingZeros64(lo))>>1 - d
+}
+
+// HasPrefix128 check if a k-mer has a prefix
+func HasPrefix128(code Code128, prefix Code128, k1, k2 int) bool {
+	if k1 <= 0 || k1 > 64 || k2 <= 0 || k2 > 64 {
+		panic(ErrKOverflow128)
+	}
+	return MustHasPrefix128(code, prefix, k1, k2)
+}
+
+// MustHasPrefix128 check if a k-mer has a prefix
+func MustHasPrefix128(code Code128, prefix Code128, k1, k2 int) bool {
+	if k1 < k2 {
+		return false
+	}
+	return code.shr(uint(k1-k2)<<1) == prefix
+}
@@ -0,0 +1,168 @@
+// Copyright © 2018-2021 Wei Shen <shenwei356@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package kmers
+
+import (
+	"bytes"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+var randomMers128 [][]byte
+
+func init() {
+	randomMers128 = make([][]byte, 10000)
+	for i := range randomMers128 {
+		randomMers128[i] = make([]byte, rand.Intn(64)+1)
+		for j := range randomMers128[i] {
+			randomMers128[i][j] = bit2base[rand.Intn(4)]
+		}
+	}
+}
+
+func revCompBytes(s []byte) []byte {
+	rc := make([]byte, len(s))
+	for i, b := range s {
+		rc[len(s)-1-i] = bit2base[base2bit[b]^3]
+	}
+	return rc
+}
+
+func TestEncodeDecode128(t *testing.T) {
+	for _, mer := range randomMers128 {
+		kcode, err := NewKmerCode128(mer)
+		if err != nil {
+			t.Errorf("Encode128 error: %s", mer)
+		}
+		if !bytes.Equal(mer, kcode.Bytes()) {
+			t.Errorf("Decode128 error: %s != %s ", mer, kcode.Bytes())
+		}
+		if len(mer) <= 32 {
+			code, _ := Encode(mer)
+			if kcode.Code.Hi != 0 || kcode.Code.Lo != code {
+				t.Errorf("Encode128 error: %s, not compatible with Encode", mer)
+			}
+		}
+		rc := kcode.RevComp().Bytes()
+		if !bytes.Equal(rc, revCompBytes(mer)) {
+			t.Errorf("RevComp error: %s, %s", mer, rc)
+		}
+		if !kcode.Rev().Rev().Equal(kcode) || !kcode.Comp().Comp().Equal(kcode) {
+			t.Errorf("Rev() or Comp() error: %s", kcode)
+		}
+		if !kcode.Comp().Rev().Equal(kcode.RevComp()) {
+			t.Errorf("Rev().Comp() error: %s", kcode)
+		}
+		if c := kcode.Canonical().String(); c > string(mer) || c > string(rc) {
+			t.Errorf("Canonical error: %s, %s", kcode, c)
+		}
+	}
+
+	if _, err := Encode128(bytes.Repeat([]byte("A"), 65)); err != ErrKOverflow128 {
+		t.Errorf("Encode128 should fail for k=65")
+	}
+}
+
+func TestEncodeFromFormerAndLatterKmer128(t *testing.T) {
+	seq := bytes.Repeat(benchMer, 4)
+	for _, k := range []int{1, 31, 32, 33, 63, 64} {
+		var pCode Code128
+		for i := 0; i+k <= len(seq); i++ {
+			kmer := seq[i : i+k]
+			code0, _ := Encode128(kmer)
+			if i > 0 {
+				code, err := EncodeFromFormerKmer128(kmer, seq[i-1:i-1+k], pCode)
+				if err != nil || code != code0 {
+					t.Errorf("EncodeFromFormerKmer128 error for %s", kmer)
+				}
+			}
+			pCode = code0
+		}
+
+		for i := len(seq) - k; i >= 0; i-- {
+			kmer := seq[i : i+k]
+			code0, _ := Encode128(kmer)
+			if i < len(seq)-k {
+				code, err := EncodeFromLatterKmer128(kmer, seq[i+1:i+1+k], pCode)
+				if err != nil || code != code0 {
+					t.Errorf("EncodeFromLatterKmer128 error for %s", kmer)
+				}
+			}
+			pCode = code0
+		}
+	}
+}
+
+func TestSubstringOps128(t *testing.T) {
+	kmer := []byte("ACTGACCTGCACTGACCTGCACTGACCTGCACTGACCTGCTTGA")
+	code, _ := Encode128(kmer)
+	k := len(kmer)
+
+	for i, b := range kmer {
+		if c := BaseAt128(code, k, i); bit2base[c] != b {
+			t.Errorf("BaseAt128 error: %d, expected %c, returned %c", i, b, bit2base[c])
+		}
+	}
+
+	for i := 1; i <= k; i++ {
+		p := MustDecode128(Prefix128(code, k, i), i)
+		if !bytes.Equal(p, kmer[:i]) {
+			t.Errorf("Prefix128 error: %d, expected %s, returned %s", i, kmer[:i], p)
+		}
+		if !HasPrefix128(code, Prefix128(code, k, i), k, i) {
+			t.Errorf("HasPrefix128 error: %d", i)
+		}
+	}
+
+	for i := 0; i < k; i++ {
+		s := MustDecode128(Suffix128(code, k, i), k-i)
+		if !bytes.Equal(s, kmer[i:]) {
+			t.Errorf("Suffix128 error: %d, expected %s, returned %s", i, kmer[i:], s)
+		}
+	}
+
+	kmer2 := []byte("ACTGACCTGCACTGACCTGCACTGACCTGCACTGAGG")
+	code2, _ := Encode128(kmer2)
+	if n := LongestPrefix128(code, code2, k, len(kmer2)); n != 35 {
+		t.Errorf("LongestPrefix128 error: expected %d, returned %d", 35, n)
+	}
+	if n := LongestPrefix128(code2, code, len(kmer2), k); n != 35 {
+		t.Errorf("LongestPrefix128 error: expected %d, returned %d", 35, n)
+	}
+	if HasPrefix128(code, code2, k, len(kmer2)) {
+		t.Errorf("HasPrefix128 error: expected %v, returned %v", false, true)
+	}
+}
+
+func TestCode128Slice(t *testing.T) {
+	codes := make(Code128Slice, len(randomMers128))
+	for i, mer := range randomMers128 {
+		codes[i], _ = Encode128(mer)
+	}
+	sort.Sort(codes)
+	for i := 1; i < len(codes); i++ {
+		if codes[i].Less(codes[i-1]) {
+			t.Errorf("Code128Slice sorting error")
+			break
+		}
+	}
+}
@@ -20,10 +20,6 @@
 
 package kmers
 
-import (
-	"bytes"
-)
-
 // KmerCode is a struct representing a k-mer in 64-bits.
 type KmerCode struct {
 	Code uint64
@@ -39,6 +35,16 @@ func NewKmerCode(kmer []byte) (KmerCode, error) {
 	return KmerCode{code, len(kmer)}, err
 }
 
+// NewKmerCodeWithPolicy is similar to NewKmerCode,
+// but handles degenerate bases with the given policy.
+func NewKmerCodeWithPolicy(kmer []byte, policy EncodePolicy) (KmerCode, error) {
+	code, err := EncodeWithPolicy(kmer, policy)
+	if err != nil {
+		return KmerCode{}, err
+	}
+	return KmerCode{code, len(kmer)}, err
+}
+
 // NewKmerCodeFromFormerOne computes KmerCode from the Former consecutive k-mer.
 func NewKmerCodeFromFormerOne(kmer []byte, leftKmer []byte, preKcode KmerCode) (KmerCode, error) {
 	code, err := EncodeFromFormerKmer(kmer, leftKmer, preKcode.Code)
@@ -58,6 +64,26 @@ func NewKmerCodeMustFromFormerOne(kmer []byte, leftKmer []byte, preKcode KmerCod
 	return KmerCode{code, len(kmer)}, err
 }
 
+// NewKmerCodeFromFormerOneWithPolicy is similar to NewKmerCodeFromFormerOne,
+// but handles degenerate bases with the given policy.
+func NewKmerCodeFromFormerOneWithPolicy(kmer []byte, leftKmer []byte, preKcode KmerCode, policy EncodePolicy) (KmerCode, error) {
+	code, err := EncodeFromFormerKmerWithPolicy(kmer, leftKmer, preKcode.Code, policy)
+	if err != nil {
+		return KmerCode{}, err
+	}
+	return KmerCode{code, len(kmer)}, err
+}
+
+// NewKmerCodeMustFromFormerOneWithPolicy is similar to NewKmerCodeMustFromFormerOne,
+// but handles degenerate bases with the given policy.
+func NewKmerCodeMustFromFormerOneWithPolicy(kmer []byte, leftKmer []byte, preKcode KmerCode, policy EncodePolicy) (KmerCode, error) {
+	code, err := MustEncodeFromFormerKmerWithPolicy(kmer, leftKmer, preKcode.Code, policy)
+	if err != nil {
+		return KmerCode{}, err
+	}
+	return KmerCode{code, len(kmer)}, err
+}
+
 // Equal checks wether two KmerCodes are the same.
 func (kcode KmerCode) Equal(kcode2 KmerCode) bool {
 	return kcode.K == kcode2.K && kcode.Code == kcode2.Code
@@ -87,6 +113,32 @@ func (kcode KmerCode) Canonical() KmerCode {
 	return kcode
 }
 
+// HasSuffix checks if the k-mer has a suffix.
+func (kcode KmerCode) HasSuffix(suffix KmerCode) bool {
+	return HasSuffix(kcode.Code, suffix.Code, kcode.K, suffix.K)
+}
+
+// LongestSuffix returns the length of the longest common suffix.
+func (kcode KmerCode) LongestSuffix(kcode2 KmerCode) int {
+	return LongestSuffix(kcode.Code, kcode2.Code, kcode.K, kcode2.K)
+}
+
+// Substr returns the substring of n bases starting from position i (0-based).
+func (kcode KmerCode) Substr(i int, n int) KmerCode {
+	return KmerCode{Substr(kcode.Code, kcode.K, i, n), n}
+}
+
+// Concat returns the concatenation of two k-mers.
+func (kcode KmerCode) Concat(kcode2 KmerCode) KmerCode {
+	return KmerCode{Concat(kcode.Code, kcode2.Code, kcode.K, kcode2.K), kcode.K + kcode2.K}
+}
+
+// Overlap returns the length of the longest suffix
+// which is also a prefix of kcode2, 0 for no overlap.
+func (kcode KmerCode) Overlap(kcode2 KmerCode) int {
+	return Overlap(kcode.Code, kcode2.Code, kcode.K, kcode2.K)
+}
+
 // Bytes returns k-mer in []byte.
 func (kcode KmerCode) Bytes() []byte {
 	return Decode(kcode.Code, kcode.K)
@@ -94,14 +146,28 @@ func (kcode KmerCode) Bytes() []byte {
 
 // String returns k-mer in string
 func (kcode KmerCode) String() string {
-	return string(Decode(kcode.Code, kcode.K))
+	var buf [32]byte
+	return string(AppendDecode(buf[:0], kcode.Code, kcode.K))
+}
+
+// AppendBytes appends the k-mer to dst and returns the extended buffer.
+func (kcode KmerCode) AppendBytes(dst []byte) []byte {
+	return AppendDecode(dst, kcode.Code, kcode.K)
 }
 
 // BitsString returns code to string
 func (kcode KmerCode) BitsString() string {
-	var buf bytes.Buffer
-	for _, b := range Decode(kcode.Code, kcode.K) {
-		buf.WriteString(bit2str[base2bit[b]])
+	if kcode.K <= 0 || kcode.K > 32 {
+		panic(ErrKOverflow)
+	}
+	if kcode.Code > MaxCode[kcode.K] {
+		panic(ErrCodeOverflow)
+	}
+	var buf [64]byte
+	code := kcode.Code
+	for i := kcode.K<<1 - 1; i >= 0; i-- {
+		buf[i] = '0' + byte(code&1)
+		code >>= 1
 	}
-	return buf.String()
+	return string(buf[:kcode.K<<1])
 }
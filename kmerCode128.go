@@ -0,0 +1,107 @@
+// Copyright © 2018-2021 Wei Shen <shenwei356@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package kmers
+
+import (
+	"bytes"
+)
+
+// KmerCode128 is a struct representing a k-mer (k<=64) in 128-bits.
+type KmerCode128 struct {
+	Code Code128
+	K    int
+}
+
+// NewKmerCode128 returns a new KmerCode128 struct from byte slice.
+func NewKmerCode128(kmer []byte) (KmerCode128, error) {
+	code, err := Encode128(kmer)
+	if err != nil {
+		return KmerCode128{}, err
+	}
+	return KmerCode128{code, len(kmer)}, err
+}
+
+// NewKmerCode128FromFormerOne computes KmerCode128 from the Former consecutive k-mer.
+func NewKmerCode128FromFormerOne(kmer []byte, leftKmer []byte, preKcode KmerCode128) (KmerCode128, error) {
+	code, err := EncodeFromFormerKmer128(kmer, leftKmer, preKcode.Code)
+	if err != nil {
+		return KmerCode128{}, err
+	}
+	return KmerCode128{code, len(kmer)}, err
+}
+
+// NewKmerCode128MustFromFormerOne computes KmerCode128 from the Former consecutive k-mer,
+// assuming the k-mer and leftKmer are both OK.
+func NewKmerCode128MustFromFormerOne(kmer []byte, leftKmer []byte, preKcode KmerCode128) (KmerCode128, error) {
+	code, err := MustEncodeFromFormerKmer128(kmer, leftKmer, preKcode.Code)
+	if err != nil {
+		return KmerCode128{}, err
+	}
+	return KmerCode128{code, len(kmer)}, err
+}
+
+// Equal checks wether two KmerCode128s are the same.
+func (kcode KmerCode128) Equal(kcode2 KmerCode128) bool {
+	return kcode.K == kcode2.K && kcode.Code == kcode2.Code
+}
+
+// Rev returns KmerCode128 of the reverse sequence.
+func (kcode KmerCode128) Rev() KmerCode128 {
+	return KmerCode128{MustReverse128(kcode.Code, kcode.K), kcode.K}
+}
+
+// Comp returns KmerCode128 of the complement sequence.
+func (kcode KmerCode128) Comp() KmerCode128 {
+	return KmerCode128{MustComplement128(kcode.Code, kcode.K), kcode.K}
+}
+
+// RevComp returns KmerCode128 of the reverse complement sequence.
+func (kcode KmerCode128) RevComp() KmerCode128 {
+	return KmerCode128{MustRevComp128(kcode.Code, kcode.K), kcode.K}
+}
+
+// Canonical returns its canonical kmer
+func (kcode KmerCode128) Canonical() KmerCode128 {
+	rcKcode := kcode.RevComp()
+	if rcKcode.Code.Less(kcode.Code) {
+		return rcKcode
+	}
+	return kcode
+}
+
+// Bytes returns k-mer in []byte.
+func (kcode KmerCode128) Bytes() []byte {
+	return Decode128(kcode.Code, kcode.K)
+}
+
+// String returns k-mer in string
+func (kcode KmerCode128) String() string {
+	return string(Decode128(kcode.Code, kcode.K))
+}
+
+// BitsString returns code to string
+func (kcode KmerCode128) BitsString() string {
+	var buf bytes.Buffer
+	for _, b := range Decode128(kcode.Code, kcode.K) {
+		buf.WriteString(bit2str[base2bit[b]])
+	}
+	return buf.String()
+}
@@ -75,6 +75,25 @@ func TestEncodeDecode(t *testing.T) {
 	}
 }
 
+func TestDecodeCodeOverflow(t *testing.T) {
+	for k := 1; k <= 32; k++ {
+		if s := Decode(MaxCode[k], k); !bytes.Equal(s, bytes.Repeat([]byte("T"), k)) {
+			t.Errorf("Decode error: k=%d, %s", k, s)
+		}
+		if k == 32 {
+			break
+		}
+		func() {
+			defer func() {
+				if r := recover(); r != ErrCodeOverflow {
+					t.Errorf("Decode should panic with ErrCodeOverflow for k=%d, code=%d", k, MaxCode[k]+1)
+				}
+			}()
+			Decode(MaxCode[k]+1, k)
+		}()
+	}
+}
+
 // TestEncodeFromFormerKmer tests TestEncodeFromFormerKmer
 func TestEncodeFromFormerKmer(t *testing.T) {
 	var err error
@@ -169,6 +188,106 @@ func TestRevComp(t *testing.T) {
 	}
 }
 
+func TestEncodePolicy(t *testing.T) {
+	kmer := []byte("ACGTNACGT")
+
+	if _, err := EncodeWithPolicy(kmer, Strict); err != ErrIllegalBase {
+		t.Errorf("EncodeWithPolicy should fail for %s in Strict policy", kmer)
+	}
+	if _, err := EncodeWithPolicy(kmer, Skip); err != ErrIllegalBase {
+		t.Errorf("EncodeWithPolicy should fail for %s in Skip policy", kmer)
+	}
+	code, err := EncodeWithPolicy(kmer, Lenient)
+	if err != nil {
+		t.Errorf("EncodeWithPolicy should not fail for %s in Lenient policy", kmer)
+	}
+	if s := string(Decode(code, len(kmer))); s != "ACGTAACGT" {
+		t.Errorf("EncodeWithPolicy error for %s in Lenient policy: %s", kmer, s)
+	}
+
+	// the default tables are not changed
+	if code2, err := Encode(kmer); err != nil || code2 != code {
+		t.Errorf("Encode should not be affected by policies")
+	}
+	if _, err := EncodeWithPolicy([]byte("ACGU"), Strict); err != ErrIllegalBase {
+		t.Errorf("EncodeWithPolicy should fail for U in Strict policy")
+	}
+
+	for _, policy := range []EncodePolicy{Strict, Skip} {
+		if _, err := NewKmerCodeWithPolicy(kmer, policy); err != ErrIllegalBase {
+			t.Errorf("NewKmerCodeWithPolicy should fail for %s in policy %d", kmer, policy)
+		}
+		if _, err := MustEncodeFromFormerKmerWithPolicy(kmer[0:5], kmer[0:5], 0, policy); err != ErrIllegalBase {
+			t.Errorf("MustEncodeFromFormerKmerWithPolicy should fail for %s in policy %d", kmer[0:5], policy)
+		}
+		if _, err := EncodeFromFormerKmerWithPolicy(kmer[0:5], []byte("AACGT"), 0, policy); err != ErrIllegalBase {
+			t.Errorf("EncodeFromFormerKmerWithPolicy should fail for %s in policy %d", kmer[0:5], policy)
+		}
+		if _, err := NewKmerCodeFromFormerOneWithPolicy(kmer[1:5], kmer[0:4], KmerCode{}, policy); err != ErrIllegalBase {
+			t.Errorf("NewKmerCodeFromFormerOneWithPolicy should fail for %s in policy %d", kmer[1:5], policy)
+		}
+		if _, err := NewKmerCodeMustFromFormerOneWithPolicy(kmer[1:5], kmer[0:4], KmerCode{}, policy); err != ErrIllegalBase {
+			t.Errorf("NewKmerCodeMustFromFormerOneWithPolicy should fail for %s in policy %d", kmer[1:5], policy)
+		}
+		if _, err := MustEncodeFromLatterKmerWithPolicy(kmer[4:9], kmer[5:], 0, policy); err != ErrIllegalBase {
+			t.Errorf("MustEncodeFromLatterKmerWithPolicy should fail for %s in policy %d", kmer[4:9], policy)
+		}
+		if _, err := EncodeFromLatterKmerWithPolicy(kmer[4:8], kmer[5:9], 0, policy); err != ErrIllegalBase {
+			t.Errorf("EncodeFromLatterKmerWithPolicy should fail for %s in policy %d", kmer[4:8], policy)
+		}
+	}
+
+	// rolling encoders in Lenient policy
+	code5, _ := EncodeWithPolicy(kmer[0:5], Lenient)
+	code5Next, _ := Encode(kmer[1:6])
+	if c, err := EncodeFromFormerKmerWithPolicy(kmer[1:6], kmer[0:5], code5, Lenient); err != nil || c != code5Next {
+		t.Errorf("EncodeFromFormerKmerWithPolicy error in Lenient policy")
+	}
+	if c, err := EncodeFromLatterKmerWithPolicy(kmer[0:5], kmer[1:6], code5Next, Lenient); err != nil || c != code5 {
+		t.Errorf("EncodeFromLatterKmerWithPolicy error in Lenient policy")
+	}
+
+	positions := func(iter *KmerIterator) []int {
+		var poss []int
+		for {
+			_, pos, ok := iter.Next()
+			if !ok {
+				break
+			}
+			poss = append(poss, pos)
+		}
+		return poss
+	}
+
+	iter, _ := NewKmerIterator(kmer, 3)
+	if poss := positions(iter); len(poss) != 7 || iter.Err() != nil {
+		t.Errorf("KmerIterator should keep the degenerate base in Lenient policy, returned positions: %v", poss)
+	}
+
+	iter.Reset(kmer)
+	iter.SetPolicy(Strict)
+	if poss := positions(iter); len(poss) != 2 || iter.Err() != ErrIllegalBase {
+		t.Errorf("KmerIterator should stop at the illegal base in Strict policy")
+	}
+
+	iter.Reset(kmer)
+	iter.SetPolicy(Skip)
+	if poss := positions(iter); fmt.Sprintf("%v", poss) != "[0 1 5 6]" || iter.Err() != nil {
+		t.Errorf("KmerIterator should skip the illegal base in Skip policy, returned positions: %v", poss)
+	}
+
+	citer, _ := NewCanonicalKmerIterator(kmer, 3)
+	citer.SetPolicy(Strict)
+	for {
+		if _, _, _, ok := citer.Next(); !ok {
+			break
+		}
+	}
+	if citer.Err() != ErrIllegalBase {
+		t.Errorf("CanonicalKmerIterator should stop at the illegal base in Strict policy")
+	}
+}
+
 func parseKmer(s string) ([]byte, uint64, int) {
 	kmer := []byte(s)
 	code, _ := Encode(kmer)
@@ -176,6 +295,14 @@ func parseKmer(s string) ([]byte, uint64, int) {
 	return kmer, code, k
 }
 
+func mustEncode(kmer []byte) uint64 {
+	code, err := Encode(kmer)
+	if err != nil {
+		panic(err)
+	}
+	return code
+}
+
 func TestSubstringOps(t *testing.T) {
 	kmer, code, k := parseKmer("ACTGACCTGC")
 
@@ -235,6 +362,130 @@ func TestSubstringOps(t *testing.T) {
 	}
 }
 
+func TestSuffixAndOverlapOps(t *testing.T) {
+	kmer, code, k := parseKmer("ACTGACCTGC")
+	_, s1, k1 := parseKmer("CCTGC")
+	_, s2, k2 := parseKmer("ACCTGA")
+
+	// HasSuffix
+	if !HasSuffix(code, s1, k, k1) {
+		t.Errorf("HasSuffix error: expected %v, returned %v", true, false)
+	}
+	if HasSuffix(code, s2, k, k2) {
+		t.Errorf("HasSuffix error: expected %v, returned %v", false, true)
+	}
+
+	// LongestSuffix
+	if n := LongestSuffix(code, s1, k, k1); n != 5 {
+		t.Errorf("LongestSuffix error: expected %d, returned %d", 5, n)
+	}
+	if n := LongestSuffix(code, s2, k, k2); n != 0 {
+		t.Errorf("LongestSuffix error: expected %d, returned %d", 0, n)
+	}
+	if n := LongestSuffix(code, code, k, k); n != k {
+		t.Errorf("LongestSuffix error: expected %d, returned %d", k, n)
+	}
+
+	// Substr
+	for i := 0; i < k; i++ {
+		for n := 1; i+n <= k; n++ {
+			s := MustDecode(Substr(code, k, i, n), n)
+			if !bytes.Equal(s, kmer[i:i+n]) {
+				t.Errorf("Substr error: %d, %d, expected %s, returned %s", i, n, kmer[i:i+n], s)
+			}
+		}
+	}
+
+	// Concat
+	kcode := KmerCode{code, k}.Concat(KmerCode{s2, k2})
+	if kcode.String() != "ACTGACCTGCACCTGA" {
+		t.Errorf("Concat error: expected %s, returned %s", "ACTGACCTGCACCTGA", kcode)
+	}
+
+	// Overlap
+	for _, c := range []struct {
+		a, b string
+		n    int
+	}{
+		{"ACTGACCTGC", "CTGCAAT", 4},
+		{"ACTGACCTGC", "GCAAT", 2},
+		{"ACTGACCTGC", "AAAAA", 0},
+		{"ACGT", "ACGT", 4},
+		{"AAAA", "AAAAAAAAAAAA", 4},
+	} {
+		kc1, _ := NewKmerCode([]byte(c.a))
+		kc2, _ := NewKmerCode([]byte(c.b))
+		if n := kc1.Overlap(kc2); n != c.n {
+			t.Errorf("Overlap error: %s, %s, expected %d, returned %d", c.a, c.b, c.n, n)
+		}
+	}
+}
+
+func TestSuccessorsAndPredecessors(t *testing.T) {
+	for _, mer := range randomMers[:1000] {
+		code, _ := Encode(mer)
+		k := len(mer)
+
+		succs := Successors(code, k)
+		preds := Predecessors(code, k)
+		csuccs, srcs := CanonicalSuccessors(code, k)
+		cpreds, prcs := CanonicalPredecessors(code, k)
+		for b := 0; b < 4; b++ {
+			next := append(append([]byte{}, mer[1:]...), bit2base[b])
+			prev := append([]byte{bit2base[b]}, mer[:k-1]...)
+			if s := MustDecode(succs[b], k); !bytes.Equal(s, next) {
+				t.Errorf("Successors error: %s, expected %s, returned %s", mer, next, s)
+			}
+			if s := MustDecode(preds[b], k); !bytes.Equal(s, prev) {
+				t.Errorf("Predecessors error: %s, expected %s, returned %s", mer, prev, s)
+			}
+			if csuccs[b] != Canonical(succs[b], k) || srcs[b] != (csuccs[b] != succs[b]) {
+				t.Errorf("CanonicalSuccessors error: %s, %s", mer, next)
+			}
+			if cpreds[b] != Canonical(preds[b], k) || prcs[b] != (cpreds[b] != preds[b]) {
+				t.Errorf("CanonicalPredecessors error: %s, %s", mer, prev)
+			}
+		}
+	}
+}
+
+func TestHammingDistance(t *testing.T) {
+	for i := 1; i < len(randomMers); i++ {
+		mer1, mer2 := randomMers[i-1], randomMers[i]
+		if len(mer2) < len(mer1) {
+			mer1 = mer1[:len(mer2)]
+		} else {
+			mer2 = mer2[:len(mer1)]
+		}
+		k := len(mer1)
+		code1, _ := Encode(mer1)
+		code2, _ := Encode(mer2)
+
+		var poss []int
+		for j := range mer1 {
+			if mer1[j] != mer2[j] {
+				poss = append(poss, j)
+			}
+		}
+		if d := HammingDistance(code1, code2, k); d != len(poss) {
+			t.Errorf("HammingDistance error: %s, %s, expected %d, returned %d", mer1, mer2, len(poss), d)
+		}
+		if p := MismatchPositions(code1, code2, k); fmt.Sprintf("%v", p) != fmt.Sprintf("%v", poss) {
+			t.Errorf("MismatchPositions error: %s, %s, expected %v, returned %v", mer1, mer2, poss, p)
+		}
+	}
+
+	codes := CodeSlice{mustEncode([]byte("ACGTACGT")), mustEncode([]byte("ACGTACGA")),
+		mustEncode([]byte("TCGTACGA")), mustEncode([]byte("TTTTTTTT"))}
+	query := mustEncode([]byte("ACGTACGT"))
+	if d := AppendHammingDistances(nil, query, codes, 8); fmt.Sprintf("%v", d) != "[0 1 2 6]" {
+		t.Errorf("AppendHammingDistances error: %v", d)
+	}
+	if idx := HammingSearch(query, codes, 8, 1); fmt.Sprintf("%v", idx) != "[0 1]" {
+		t.Errorf("HammingSearch error: %v", idx)
+	}
+}
+
 var result uint64
 
 // BenchmarkEncode tests speed of Encode()
@@ -0,0 +1,233 @@
+// Copyright © 2018-2021 Wei Shen <shenwei356@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package kmers
+
+import (
+	"errors"
+)
+
+// ErrEmptyKmer means the k-mer is empty.
+var ErrEmptyKmer = errors.New("kmers: empty k-mer")
+
+// LongKmer is a k-mer of arbitrary length, with bases packed into
+// 64-bit words in the same 2-bit encoding as Encode.
+// The i-th base (0-based) is stored in Words[i/32], from the highest bits to the lowest ones,
+// and unused bits of the last word are always 0.
+// So comparing words one by one is equal to comparing k-mers lexicographically.
+//
+// Copies of a LongKmer share the same underlying words, use Clone() if needed.
+type LongKmer struct {
+	Words []uint64
+	K     int
+}
+
+// nWords returns the number of words needed for k bases.
+func nWords(k int) int {
+	return (k + 31) >> 5
+}
+
+// tailMask returns the mask of used bits of the last word for k bases.
+func tailMask(k int) uint64 {
+	r := k & 31
+	if r == 0 {
+		return 1<<64 - 1
+	}
+	return ^uint64(0) << (uint(32-r) << 1)
+}
+
+// NewLongKmer returns a new LongKmer from byte slice.
+// Degenerate bases are handled in the same way as Encode.
+func NewLongKmer(kmer []byte) (LongKmer, error) {
+	if len(kmer) == 0 {
+		return LongKmer{}, ErrEmptyKmer
+	}
+
+	words := make([]uint64, nWords(len(kmer)))
+	var v uint64
+	for i, b := range kmer {
+		v = base2bit[b]
+		if v == 4 {
+			return LongKmer{}, ErrIllegalBase
+		}
+		words[i>>5] |= v << (uint(31-i&31) << 1)
+	}
+	return LongKmer{words, len(kmer)}, nil
+}
+
+// Clone returns a copy of the LongKmer.
+func (kmer LongKmer) Clone() LongKmer {
+	words := make([]uint64, len(kmer.Words))
+	copy(words, kmer.Words)
+	return LongKmer{words, kmer.K}
+}
+
+// BaseAt returns the base in pos i (0-based).
+func (kmer LongKmer) BaseAt(i int) uint8 {
+	if i < 0 || i >= kmer.K {
+		panic(ErrPositionOverflow)
+	}
+	return uint8(kmer.Words[i>>5] >> (uint(31-i&31) << 1) & 3)
+}
+
+// Bytes returns k-mer in []byte.
+func (kmer LongKmer) Bytes() []byte {
+	s := make([]byte, kmer.K)
+	for i := range s {
+		s[i] = bit2base[kmer.Words[i>>5]>>(uint(31-i&31)<<1)&3]
+	}
+	return s
+}
+
+// String returns k-mer in string.
+func (kmer LongKmer) String() string {
+	return string(kmer.Bytes())
+}
+
+// Compare compares two k-mers lexicographically.
+// The result will be 0 if kmer==kmer2, -1 if kmer < kmer2, and +1 if kmer > kmer2.
+func (kmer LongKmer) Compare(kmer2 LongKmer) int {
+	k := kmer.K
+	if kmer2.K < k {
+		k = kmer2.K
+	}
+
+	n := nWords(k)
+	var w1, w2 uint64
+	for i := 0; i < n; i++ {
+		w1, w2 = kmer.Words[i], kmer2.Words[i]
+		if i == n-1 {
+			w1 &= tailMask(k)
+			w2 &= tailMask(k)
+		}
+		if w1 < w2 {
+			return -1
+		}
+		if w1 > w2 {
+			return 1
+		}
+	}
+
+	if kmer.K < kmer2.K {
+		return -1
+	}
+	if kmer.K > kmer2.K {
+		return 1
+	}
+	return 0
+}
+
+// Equal checks wether two LongKmers are the same.
+func (kmer LongKmer) Equal(kmer2 LongKmer) bool {
+	return kmer.Compare(kmer2) == 0
+}
+
+// Less checks if kmer is lexicographically smaller than kmer2.
+func (kmer LongKmer) Less(kmer2 LongKmer) bool {
+	return kmer.Compare(kmer2) < 0
+}
+
+// shiftWordsLeft shifts all bits of words left by n bits in place.
+func shiftWordsLeft(words []uint64, n int) {
+	d, r := n>>6, uint(n&63)
+	for i := range words {
+		if i+d >= len(words) {
+			words[i] = 0
+			continue
+		}
+		words[i] = words[i+d] << r
+		if r > 0 && i+d+1 < len(words) {
+			words[i] |= words[i+d+1] >> (64 - r)
+		}
+	}
+}
+
+// RevComp returns the reverse complement sequence.
+func (kmer LongKmer) RevComp() LongKmer {
+	n := len(kmer.Words)
+	words := make([]uint64, n)
+	for i, w := range kmer.Words {
+		words[n-1-i] = MustRevComp(w, 32)
+	}
+	// remove the complement bases of unused bits.
+	shiftWordsLeft(words, ((n<<5)-kmer.K)<<1)
+	return LongKmer{words, kmer.K}
+}
+
+// Canonical returns its canonical k-mer.
+func (kmer LongKmer) Canonical() LongKmer {
+	rc := kmer.RevComp()
+	if rc.Less(kmer) {
+		return rc
+	}
+	return kmer
+}
+
+// Prefix returns the first n bases. n needs to be > 0.
+func (kmer LongKmer) Prefix(n int) LongKmer {
+	if n < 1 || n > kmer.K {
+		panic(ErrLengthOverflow)
+	}
+	words := make([]uint64, nWords(n))
+	copy(words, kmer.Words)
+	words[len(words)-1] &= tailMask(n)
+	return LongKmer{words, n}
+}
+
+// Suffix returns the suffix starting from position i (0-based).
+// The length of the suffix is k - i.
+func (kmer LongKmer) Suffix(i int) LongKmer {
+	if i < 0 || i >= kmer.K {
+		panic(ErrPositionOverflow)
+	}
+	words := make([]uint64, len(kmer.Words))
+	copy(words, kmer.Words)
+	shiftWordsLeft(words, i<<1)
+	return LongKmer{words[:nWords(kmer.K-i)], kmer.K - i}
+}
+
+// RollForward removes the first base and appends a base to the end,
+// like MustEncodeFromFormerKmer does.
+func (kmer *LongKmer) RollForward(base byte) error {
+	v := base2bit[base]
+	if v == 4 {
+		return ErrIllegalBase
+	}
+	shiftWordsLeft(kmer.Words, 2)
+	i := kmer.K - 1
+	kmer.Words[i>>5] |= v << (uint(31-i&31) << 1)
+	return nil
+}
+
+// RollBackward removes the last base and inserts a base to the beginning,
+// like MustEncodeFromLatterKmer does.
+func (kmer *LongKmer) RollBackward(base byte) error {
+	v := base2bit[base]
+	if v == 4 {
+		return ErrIllegalBase
+	}
+	words := kmer.Words
+	for i := len(words) - 1; i > 0; i-- {
+		words[i] = words[i]>>2 | words[i-1]<<62
+	}
+	words[0] = words[0]>>2 | v<<62
+	words[len(words)-1] &= tailMask(kmer.K)
+	return nil
+}
@@ -0,0 +1,122 @@
+// Copyright © 2018-2021 Wei Shen <shenwei356@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package kmers
+
+import (
+	"bytes"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+func randomSeq(n int) []byte {
+	s := make([]byte, n)
+	for i := range s {
+		s[i] = bit2base[rand.Intn(4)]
+	}
+	return s
+}
+
+func TestLongKmer(t *testing.T) {
+	for _, k := range []int{1, 31, 32, 33, 63, 64, 65, 100, 128, 200} {
+		mer := randomSeq(k)
+		kmer, err := NewLongKmer(mer)
+		if err != nil {
+			t.Errorf("NewLongKmer error: %s", err)
+			continue
+		}
+		if !bytes.Equal(kmer.Bytes(), mer) {
+			t.Errorf("k=%d: decode error: %s != %s", k, kmer, mer)
+		}
+		for i, b := range mer {
+			if bit2base[kmer.BaseAt(i)] != b {
+				t.Errorf("k=%d: BaseAt error: %d", k, i)
+			}
+		}
+
+		rc := revCompBytes(mer)
+		if !bytes.Equal(kmer.RevComp().Bytes(), rc) {
+			t.Errorf("k=%d: RevComp error: %s", k, kmer.RevComp())
+		}
+		if !kmer.RevComp().RevComp().Equal(kmer) {
+			t.Errorf("k=%d: RevComp().RevComp() error", k)
+		}
+		expected := mer
+		if bytes.Compare(rc, mer) < 0 {
+			expected = rc
+		}
+		if !bytes.Equal(kmer.Canonical().Bytes(), expected) {
+			t.Errorf("k=%d: Canonical error: %s", k, kmer.Canonical())
+		}
+
+		for i := 1; i <= k; i++ {
+			if p := kmer.Prefix(i).Bytes(); !bytes.Equal(p, mer[:i]) {
+				t.Errorf("k=%d: Prefix error: %d, expected %s, returned %s", k, i, mer[:i], p)
+			}
+		}
+		for i := 0; i < k; i++ {
+			if s := kmer.Suffix(i).Bytes(); !bytes.Equal(s, mer[i:]) {
+				t.Errorf("k=%d: Suffix error: %d, expected %s, returned %s", k, i, mer[i:], s)
+			}
+		}
+
+		// rolling
+		seq := randomSeq(k + 50)
+		fwd, _ := NewLongKmer(seq[:k])
+		for i := 1; i+k <= len(seq); i++ {
+			if err = fwd.RollForward(seq[i+k-1]); err != nil {
+				t.Errorf("k=%d: RollForward error: %s", k, err)
+			}
+			if !bytes.Equal(fwd.Bytes(), seq[i:i+k]) {
+				t.Errorf("k=%d: RollForward error: %s != %s", k, fwd, seq[i:i+k])
+			}
+		}
+		bwd := fwd.Clone()
+		for i := len(seq) - k - 1; i >= 0; i-- {
+			if err = bwd.RollBackward(seq[i]); err != nil {
+				t.Errorf("k=%d: RollBackward error: %s", k, err)
+			}
+			if !bytes.Equal(bwd.Bytes(), seq[i:i+k]) {
+				t.Errorf("k=%d: RollBackward error: %s != %s", k, bwd, seq[i:i+k])
+			}
+		}
+	}
+}
+
+func TestLongKmerCompare(t *testing.T) {
+	mers := make([][]byte, 1000)
+	kmers := make(LongKmerSlice, len(mers))
+	for i := range mers {
+		mers[i] = randomSeq(rand.Intn(100) + 1)
+		if i%10 == 0 && i > 0 { // shared prefixes
+			mers[i] = append(mers[i-1][:len(mers[i-1])/2:len(mers[i-1])/2], mers[i]...)
+		}
+		kmers[i], _ = NewLongKmer(mers[i])
+	}
+	sort.Sort(kmers)
+	sort.Slice(mers, func(i, j int) bool { return bytes.Compare(mers[i], mers[j]) < 0 })
+	for i := range mers {
+		if !bytes.Equal(kmers[i].Bytes(), mers[i]) {
+			t.Errorf("Compare error: %s != %s", kmers[i], mers[i])
+			break
+		}
+	}
+}
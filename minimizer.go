@@ -0,0 +1,167 @@
+// Copyright © 2018-2021 Wei Shen <shenwei356@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package kmers
+
+import (
+	"errors"
+	"math/rand"
+)
+
+// ErrInvalidWindowSize means the window size is < 1.
+var ErrInvalidWindowSize = errors.New("kmers: invalid window size")
+
+// OrderFunc maps a k-mer code to its rank in an ordering of k-mers,
+// and k-mers with smaller ranks are preferred.
+type OrderFunc func(code uint64) uint64
+
+// LexicographicOrder orders k-mers lexicographically.
+func LexicographicOrder(code uint64) uint64 {
+	return code
+}
+
+// HashOrder orders k-mers by their hash values,
+// which avoids low-complexity k-mers like poly-A being preferred.
+func HashOrder(code uint64) uint64 {
+	return hash64(code, 1<<64-1)
+}
+
+// NewRandomOrder returns a random ordering of k-mers from a seed.
+func NewRandomOrder(seed int64) OrderFunc {
+	r := rand.New(rand.NewSource(seed)).Uint64()
+	return func(code uint64) uint64 {
+		return hash64(code^r, 1<<64-1)
+	}
+}
+
+// MinimizerIterator iterates (w,k)-minimizers of a sequence, i.e., the canonical k-mer with
+// the smallest rank in each window of w consecutive k-mers, and the leftmost one for ties.
+// A monotone deque is used, so the amortized cost of each window is O(1).
+// Windows with illegal bases are skipped.
+type MinimizerIterator struct {
+	iter  *CanonicalKmerIterator
+	w     int
+	order OrderFunc
+
+	deque   []minimizerItem // ring buffer
+	start   int             // index of the front
+	size    int             // size of the deque
+	n       int             // number of consecutive k-mers
+	prePos  int             // position of the previous k-mer
+	preMini int             // position of the previous minimizer
+}
+
+type minimizerItem struct {
+	rank uint64
+	code uint64
+	pos  int
+	rc   bool
+}
+
+// NewMinimizerIterator returns a MinimizerIterator for the sequence.
+// order can be LexicographicOrder, HashOrder, or a random one from NewRandomOrder.
+func NewMinimizerIterator(seq []byte, k int, w int, order OrderFunc) (*MinimizerIterator, error) {
+	if w < 1 {
+		return nil, ErrInvalidWindowSize
+	}
+	iter, err := NewCanonicalKmerIterator(seq, k)
+	if err != nil {
+		return nil, err
+	}
+	return &MinimizerIterator{
+		iter:    iter,
+		w:       w,
+		order:   order,
+		deque:   make([]minimizerItem, w),
+		prePos:  -2,
+		preMini: -1,
+	}, nil
+}
+
+// SetPolicy sets the policy for handling degenerate bases, see KmerIterator.
+func (iter *MinimizerIterator) SetPolicy(policy EncodePolicy) {
+	iter.iter.SetPolicy(policy)
+}
+
+// Reset resets the iterator with a new sequence, so it can be reused.
+func (iter *MinimizerIterator) Reset(seq []byte) {
+	iter.iter.Reset(seq)
+	iter.start = 0
+	iter.size = 0
+	iter.n = 0
+	iter.prePos = -2
+	iter.preMini = -1
+}
+
+// Err returns the error that stopped the iteration.
+func (iter *MinimizerIterator) Err() error {
+	return iter.iter.Err()
+}
+
+// Next returns the next minimizer, with its canonical code, position (0-based),
+// and whether it's on the reverse complement strand.
+// A minimizer shared by consecutive windows is only returned once.
+// ok is false when there's no more minimizers or an error occurred, see Err().
+func (iter *MinimizerIterator) Next() (code uint64, pos int, rc bool, ok bool) {
+	w := iter.w
+	var rank uint64
+	var back int
+	var front *minimizerItem
+	for {
+		code, pos, rc, ok = iter.iter.Next()
+		if !ok {
+			return 0, 0, false, false
+		}
+
+		if pos != iter.prePos+1 { // restarted after illegal bases
+			iter.size = 0
+			iter.n = 0
+		}
+		iter.prePos = pos
+		iter.n++
+
+		// remove k-mers out of the window from the front
+		if iter.size > 0 && iter.deque[iter.start].pos <= pos-w {
+			iter.start = (iter.start + 1) % w
+			iter.size--
+		}
+
+		// remove k-mers with bigger ranks from the back
+		rank = iter.order(code)
+		for iter.size > 0 {
+			back = (iter.start + iter.size - 1) % w
+			if iter.deque[back].rank <= rank {
+				break
+			}
+			iter.size--
+		}
+		iter.deque[(iter.start+iter.size)%w] = minimizerItem{rank, code, pos, rc}
+		iter.size++
+
+		if iter.n < w {
+			continue
+		}
+		front = &iter.deque[iter.start]
+		if front.pos != iter.preMini {
+			iter.preMini = front.pos
+			return front.code, front.pos, front.rc, true
+		}
+	}
+}
@@ -0,0 +1,107 @@
+// Copyright © 2018-2021 Wei Shen <shenwei356@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package kmers
+
+import (
+	"testing"
+)
+
+type kmerInfo struct {
+	code uint64
+	pos  int
+	rc   bool
+}
+
+// canonicalKmers returns canonical k-mers of a sequence, grouped by runs of consecutive k-mers.
+func canonicalKmers(seq []byte, k int) [][]kmerInfo {
+	var runs [][]kmerInfo
+	var run []kmerInfo
+	iter, _ := NewCanonicalKmerIterator(seq, k)
+	for {
+		code, pos, rc, ok := iter.Next()
+		if !ok {
+			break
+		}
+		if len(run) > 0 && run[len(run)-1].pos != pos-1 {
+			runs = append(runs, run)
+			run = nil
+		}
+		run = append(run, kmerInfo{code, pos, rc})
+	}
+	if len(run) > 0 {
+		runs = append(runs, run)
+	}
+	return runs
+}
+
+func TestMinimizerIterator(t *testing.T) {
+	seq := append(randomSeq(500), []byte("NNNNXAAAAAAAAAAAAAAAAAAAAAAAAX")...)
+	seq = append(seq, randomSeq(300)...)
+
+	for _, order := range []OrderFunc{LexicographicOrder, HashOrder, NewRandomOrder(11)} {
+		for _, kw := range [][2]int{{5, 1}, {11, 5}, {15, 10}, {21, 11}, {31, 50}} {
+			k, w := kw[0], kw[1]
+
+			// brute force
+			var expected []kmerInfo
+			for _, run := range canonicalKmers(seq, k) {
+				for i := 0; i+w <= len(run); i++ {
+					m := run[i]
+					for _, x := range run[i+1 : i+w] {
+						if order(x.code) < order(m.code) {
+							m = x
+						}
+					}
+					if len(expected) == 0 || expected[len(expected)-1].pos != m.pos {
+						expected = append(expected, m)
+					}
+				}
+			}
+
+			iter, err := NewMinimizerIterator(seq, k, w, order)
+			if err != nil {
+				t.Fatalf("NewMinimizerIterator error: %s", err)
+			}
+			var i int
+			for {
+				code, pos, rc, ok := iter.Next()
+				if !ok {
+					break
+				}
+				if i >= len(expected) {
+					t.Errorf("k=%d, w=%d: unexpected minimizer at %d", k, w, pos)
+					break
+				}
+				if e := expected[i]; code != e.code || pos != e.pos || rc != e.rc {
+					t.Errorf("k=%d, w=%d: expected %v, returned %v", k, w, e, kmerInfo{code, pos, rc})
+				}
+				i++
+			}
+			if i != len(expected) {
+				t.Errorf("k=%d, w=%d: expected %d minimizers, returned %d", k, w, len(expected), i)
+			}
+		}
+	}
+
+	if _, err := NewMinimizerIterator(seq, 5, 0, HashOrder); err != ErrInvalidWindowSize {
+		t.Errorf("NewMinimizerIterator should fail for w=0")
+	}
+}
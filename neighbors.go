@@ -0,0 +1,207 @@
+// Copyright © 2018-2021 Wei Shen <shenwei356@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package kmers
+
+// Neighbors returns codes of all k-mers within d mismatches (Hamming distance)
+// of a k-mer, including itself. Bases are substituted on the code directly,
+// and every neighbor is returned only once.
+func Neighbors(code uint64, k int, d int) []uint64 {
+	codes := make([]uint64, 0, numNeighbors(k, d))
+	NeighborsFunc(code, k, d, func(c uint64) {
+		codes = append(codes, c)
+	})
+	return codes
+}
+
+// NeighborsFunc is similar to Neighbors,
+// but streams codes through a callback function instead of returning a slice.
+// Nothing is returned for d < 0.
+func NeighborsFunc(code uint64, k int, d int, fn func(code uint64)) {
+	if k <= 0 || k > 32 {
+		panic(ErrKOverflow)
+	}
+	if d < 0 {
+		return
+	}
+	if d > k {
+		d = k
+	}
+	fn(code)
+	if d > 0 {
+		neighbors(code, k, 0, d, fn)
+	}
+}
+
+// neighbors substitutes bases from position i (0-based), with at most d mismatches.
+func neighbors(code uint64, k int, i int, d int, fn func(code uint64)) {
+	var c uint64
+	var shift uint
+	for ; i < k; i++ {
+		shift = uint(k-1-i) << 1
+		for x := uint64(1); x < 4; x++ {
+			c = code ^ x<<shift
+			fn(c)
+			if d > 1 {
+				neighbors(c, k, i+1, d-1, fn)
+			}
+		}
+	}
+}
+
+// numNeighbors returns the number of k-mers within d mismatches.
+func numNeighbors(k int, d int) int {
+	if d < 0 {
+		return 0
+	}
+	n, m := 1, 1 // m = C(k,i) * 3^i
+	for i := 1; i <= d && i <= k; i++ {
+		m = m * (k - i + 1) / i * 3
+		n += m
+	}
+	return n
+}
+
+// CanonicalNeighbors returns canonical codes of all k-mers within d mismatches
+// of a k-mer, including itself. As a k-mer and its reverse complement have the same
+// neighbors in canonical space, code can be either of them.
+// Every canonical code is returned only once.
+func CanonicalNeighbors(code uint64, k int, d int) []uint64 {
+	codes := make([]uint64, 0, numNeighbors(k, d))
+	CanonicalNeighborsFunc(code, k, d, func(c uint64) {
+		codes = append(codes, c)
+	})
+	return codes
+}
+
+// CanonicalNeighborsFunc is similar to CanonicalNeighbors,
+// but streams codes through a callback function instead of returning a slice.
+func CanonicalNeighborsFunc(code uint64, k int, d int, fn func(code uint64)) {
+	var rc uint64
+	NeighborsFunc(code, k, d, func(c uint64) {
+		rc = MustRevComp(c, k)
+		if c <= rc {
+			fn(c)
+			return
+		}
+		// skip it if its reverse complement is also a neighbor,
+		// which will be returned by itself.
+		if MustHammingDistance(rc, code, k) > d {
+			fn(rc)
+		}
+	})
+}
+
+// EditNeighbors returns codes of all k-mers of the same length within edit distance d
+// of a k-mer, including itself, in lexicographic order.
+// Insertions and deletions are counted, e.g., ACGTA and CGTAC have an edit distance of 2.
+// Every neighbor is returned only once.
+func EditNeighbors(code uint64, k int, d int) []uint64 {
+	codes := make([]uint64, 0, numNeighbors(k, d))
+	EditNeighborsFunc(code, k, d, func(c uint64) {
+		codes = append(codes, c)
+	})
+	return codes
+}
+
+// EditNeighborsFunc is similar to EditNeighbors,
+// but streams codes through a callback function instead of returning a slice.
+// Nothing is returned for d < 0.
+func EditNeighborsFunc(code uint64, k int, d int, fn func(code uint64)) {
+	if k <= 0 || k > 32 {
+		panic(ErrKOverflow)
+	}
+	if d < 0 {
+		return
+	}
+
+	// Neighbors are built base by base as prefix codes, with a dynamic programming
+	// column of edit distances between the prefix and all prefixes of the k-mer,
+	// and prefixes which can't reach the edit distance of d are pruned.
+	// Applying insertions and deletions on codes with Prefix/Suffix arithmetic
+	// instead would produce the same k-mer from many edit scripts and
+	// intermediate k-mers longer than 32, which need a set to remove duplicates.
+	// Here every neighbor is met only once, in lexicographic order.
+	e := &editNeighborsEnumerator{
+		k:    k,
+		d:    d,
+		fn:   fn,
+		code: code,
+		cols: make([]int, (k+1)*(k+1)),
+	}
+	for i := 0; i <= k; i++ {
+		e.cols[i] = i
+	}
+	e.extend(0, 1)
+}
+
+type editNeighborsEnumerator struct {
+	k    int
+	d    int
+	fn   func(code uint64)
+	code uint64 // code of the query k-mer
+	cols []int  // columns of all depths
+}
+
+// extend appends bases to the prefix with a code of code and a length of j-1.
+func (e *editNeighborsEnumerator) extend(code uint64, j int) {
+	k := e.k
+	prev := e.cols[(j-1)*(k+1) : j*(k+1)]
+	col := e.cols[j*(k+1) : (j+1)*(k+1)]
+
+	var i, v, best, lb int
+	for b := uint64(0); b < 4; b++ {
+		col[0] = j
+		best = j // lower bound of the final distance: col[i] + |j-i|
+		for i = 1; i <= k; i++ {
+			v = prev[i-1]
+			if e.code>>(uint(k-i)<<1)&3 != b { // base i (1-based) of the query
+				v++
+			}
+			if prev[i]+1 < v {
+				v = prev[i] + 1
+			}
+			if col[i-1]+1 < v {
+				v = col[i-1] + 1
+			}
+			col[i] = v
+
+			if i > j {
+				lb = v + i - j
+			} else {
+				lb = v + j - i
+			}
+			if lb < best {
+				best = lb
+			}
+		}
+		if best > e.d {
+			continue
+		}
+
+		if j == k {
+			if col[k] <= e.d {
+				e.fn(code<<2 | b)
+			}
+			continue
+		}
+		e.extend(code<<2|b, j+1)
+	}
+}
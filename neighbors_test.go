@@ -0,0 +1,167 @@
+// Copyright © 2018-2021 Wei Shen <shenwei356@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package kmers
+
+import (
+	"bytes"
+	"sort"
+	"testing"
+)
+
+// bytesHamming returns the Hamming distance of two k-mers.
+func bytesHamming(a, b []byte) int {
+	var n int
+	for i := range a {
+		if a[i] != b[i] {
+			n++
+		}
+	}
+	return n
+}
+
+func TestNeighbors(t *testing.T) {
+	for _, s := range []string{"A", "ACGT", "ACGTA", "AACCGGTTA"} {
+		kmer := []byte(s)
+		k := len(kmer)
+		code := mustEncode(kmer)
+		for d := 0; d <= 3; d++ {
+			// brute force
+			var expected, expectedCanonical []uint64
+			m := make(map[uint64]struct{})
+			for c := uint64(0); c <= MaxCode[k]; c++ {
+				if bytesHamming(Decode(c, k), kmer) <= d {
+					expected = append(expected, c)
+					m[Canonical(c, k)] = struct{}{}
+				}
+			}
+			for c := range m {
+				expectedCanonical = append(expectedCanonical, c)
+			}
+			sort.Sort(CodeSlice(expectedCanonical))
+
+			codes := Neighbors(code, k, d)
+			if codes[0] != code {
+				t.Errorf("%s, d=%d: the first neighbor should be itself", s, d)
+			}
+			sort.Sort(CodeSlice(codes))
+			if !equalCodes(codes, expected) {
+				t.Errorf("%s, d=%d: Neighbors error, expected %d neighbors, returned %d", s, d, len(expected), len(codes))
+			}
+
+			codes = CanonicalNeighbors(code, k, d)
+			sort.Sort(CodeSlice(codes))
+			if !equalCodes(codes, expectedCanonical) {
+				t.Errorf("%s, d=%d: CanonicalNeighbors error, expected %d neighbors, returned %d", s, d, len(expectedCanonical), len(codes))
+			}
+		}
+
+		if n := len(Neighbors(code, k, -1)) + len(CanonicalNeighbors(code, k, -1)) + len(EditNeighbors(code, k, -1)); n != 0 {
+			t.Errorf("%s, d=-1: no neighbors should be returned, returned %d", s, n)
+		}
+	}
+}
+
+func equalCodes(a, b []uint64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestNeighborsK32(t *testing.T) {
+	codes := Neighbors(benchCode, 32, 2)
+	if len(codes) != numNeighbors(32, 2) {
+		t.Errorf("Neighbors error: expected %d neighbors, returned %d", numNeighbors(32, 2), len(codes))
+	}
+	seen := make(map[uint64]struct{}, len(codes))
+	for _, c := range codes {
+		if _, ok := seen[c]; ok {
+			t.Errorf("Neighbors error: duplicated neighbor %s", Decode(c, 32))
+		}
+		seen[c] = struct{}{}
+		if bytesHamming(Decode(c, 32), bytes.ToUpper(benchMer)) > 2 {
+			t.Errorf("Neighbors error: %s", Decode(c, 32))
+		}
+	}
+}
+
+// bytesEditDistance returns the edit distance of two sequences.
+func bytesEditDistance(a, b []byte) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			v := prev[j-1]
+			if a[i-1] != b[j-1] {
+				v++
+			}
+			if prev[j]+1 < v {
+				v = prev[j] + 1
+			}
+			if cur[j-1]+1 < v {
+				v = cur[j-1] + 1
+			}
+			cur[j] = v
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+func TestEditNeighbors(t *testing.T) {
+	for _, s := range []string{"A", "ACGT", "ACGTA", "AACCGGTT"} {
+		kmer := []byte(s)
+		k := len(kmer)
+		code := mustEncode(kmer)
+		for d := 0; d <= 3; d++ {
+			var expected []uint64
+			for c := uint64(0); c <= MaxCode[k]; c++ {
+				if bytesEditDistance(Decode(c, k), kmer) <= d {
+					expected = append(expected, c)
+				}
+			}
+
+			codes := EditNeighbors(code, k, d)
+			if !equalCodes(codes, expected) {
+				t.Errorf("%s, d=%d: EditNeighbors error, expected %d neighbors, returned %d", s, d, len(expected), len(codes))
+			}
+		}
+	}
+
+	// indels
+	codes := EditNeighbors(mustEncode([]byte("ACGTA")), 5, 2)
+	for _, s := range []string{"CGTAC", "TACGT", "AGTAA", "ACGTA"} {
+		c := mustEncode([]byte(s))
+		i := sort.Search(len(codes), func(i int) bool { return codes[i] >= c })
+		if i == len(codes) || codes[i] != c {
+			t.Errorf("EditNeighbors error: %s should be a neighbor of ACGTA", s)
+		}
+	}
+}
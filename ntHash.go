@@ -0,0 +1,188 @@
+// Copyright © 2018-2021 Wei Shen <shenwei356@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package kmers
+
+import "math/bits"
+
+// seeds of bases in ntHash (Mohamadi et al. 2016, https://doi.org/10.1093/bioinformatics/btw397).
+const (
+	ntSeedA uint64 = 0x3c8bfbb395c60474
+	ntSeedC uint64 = 0x3193c18562a02b4c
+	ntSeedG uint64 = 0x20323ed082572324
+	ntSeedT uint64 = 0x295549f54be24456
+)
+
+// constants for computing multiple hashes from one, as NTM64 in ntHash.
+const (
+	ntMultiSeed  uint64 = 0x90b45d39fb6da1fa
+	ntMultiShift        = 27
+)
+
+// ntSeeds maps bases to their seeds, 0 for non-ACGT bases. U is treated as T.
+var ntSeeds = [256]uint64{
+	'A': ntSeedA, 'C': ntSeedC, 'G': ntSeedG, 'T': ntSeedT, 'U': ntSeedT,
+	'a': ntSeedA, 'c': ntSeedC, 'g': ntSeedG, 't': ntSeedT, 'u': ntSeedT,
+}
+
+// ntSeedsRC maps bases to seeds of their complement bases, 0 for non-ACGT bases.
+var ntSeedsRC = [256]uint64{
+	'A': ntSeedT, 'C': ntSeedG, 'G': ntSeedC, 'T': ntSeedA, 'U': ntSeedA,
+	'a': ntSeedT, 'c': ntSeedG, 'g': ntSeedC, 't': ntSeedA, 'u': ntSeedA,
+}
+
+// NtHash computes ntHash values of a k-mer of any length,
+// and the reverse complement of it. The canonical hash is the smaller one.
+// Only ACGT and U bases (case insensitive) are allowed, where U is treated as T.
+func NtHash(kmer []byte) (fh uint64, rh uint64, err error) {
+	if len(kmer) == 0 {
+		return 0, 0, ErrEmptyKmer
+	}
+	k := len(kmer)
+	var h uint64
+	for i, b := range kmer {
+		h = ntSeeds[b]
+		if h == 0 {
+			return 0, 0, ErrIllegalBase
+		}
+		fh ^= bits.RotateLeft64(h, k-1-i)
+		rh ^= bits.RotateLeft64(ntSeedsRC[b], i)
+	}
+	return fh, rh, nil
+}
+
+// NtHashRoll computes ntHash values of the next k-mer from these of the
+// current one, by removing the first base out and appending the base in.
+// Both bases must be ACGT, which are not checked.
+func NtHashRoll(fh uint64, rh uint64, k int, out byte, in byte) (uint64, uint64) {
+	fh = bits.RotateLeft64(fh, 1) ^ bits.RotateLeft64(ntSeeds[out], k) ^ ntSeeds[in]
+	rh = bits.RotateLeft64(rh, -1) ^ bits.RotateLeft64(ntSeedsRC[out], -1) ^
+		bits.RotateLeft64(ntSeedsRC[in], k-1)
+	return fh, rh
+}
+
+// NtMultiHash fills hashes with multiple hash values computed from a
+// ntHash value of a k-mer, which are useful for Bloom filters.
+// The first one is the hash itself.
+func NtMultiHash(hash uint64, k int, hashes []uint64) {
+	if len(hashes) == 0 {
+		return
+	}
+	hashes[0] = hash
+	var t uint64
+	for i := 1; i < len(hashes); i++ {
+		t = hash * (uint64(i) ^ uint64(k)*ntMultiSeed)
+		t ^= t >> ntMultiShift
+		hashes[i] = t
+	}
+}
+
+// NtHashIterator iterates ntHash values of all k-mers of a sequence with
+// rolling hashes, where k can be any positive integer.
+// Non-ACGT bases are skipped, and the iteration restarts from the next base,
+// unless the encode policy is Strict, where the iteration stops with an error.
+// The default policy is Lenient, and it can be changed with SetPolicy.
+type NtHashIterator struct {
+	seq       []byte
+	k         int
+	canonical bool
+	policy    EncodePolicy
+
+	i   int    // index of the next base to read
+	n   int    // number of consecutive legal bases, up to k
+	fh  uint64 // forward hash of the last n bases
+	rh  uint64 // reverse hash of the last n bases
+	err error
+}
+
+// NewNtHashIterator returns a NtHashIterator for the sequence.
+// If canonical is true, the smaller one of the forward and reverse hashes is returned.
+func NewNtHashIterator(seq []byte, k int, canonical bool) (*NtHashIterator, error) {
+	if k <= 0 {
+		return nil, ErrKOverflow
+	}
+	return &NtHashIterator{seq: seq, k: k, canonical: canonical}, nil
+}
+
+// SetPolicy sets the policy for handling non-ACGT bases.
+// Degenerate bases are always skipped, and U is treated as T only in Lenient policy.
+func (iter *NtHashIterator) SetPolicy(policy EncodePolicy) {
+	iter.policy = policy
+}
+
+// Reset resets the iterator with a new sequence, so it can be reused.
+func (iter *NtHashIterator) Reset(seq []byte) {
+	iter.seq = seq
+	iter.i = 0
+	iter.n = 0
+	iter.fh = 0
+	iter.rh = 0
+	iter.err = nil
+}
+
+// K returns the k-mer size.
+func (iter *NtHashIterator) K() int {
+	return iter.k
+}
+
+// Err returns the error that stopped the iteration,
+// i.e., ErrIllegalBase in the Strict encode policy.
+func (iter *NtHashIterator) Err() error {
+	return iter.err
+}
+
+// Next returns the hash of the next k-mer and its position (0-based) in the sequence.
+// ok is false when there's no more k-mers or an error occurred, see Err().
+func (iter *NtHashIterator) Next() (hash uint64, pos int, ok bool) {
+	var b byte
+	for iter.i < len(iter.seq) {
+		b = iter.seq[iter.i]
+		iter.i++
+
+		if ntSeeds[b] == 0 || (iter.policy != Lenient && (b == 'U' || b == 'u')) {
+			if iter.policy == Strict {
+				iter.err = ErrIllegalBase
+				iter.i = len(iter.seq)
+				break
+			}
+			// restart from the next base
+			iter.n = 0
+			iter.fh = 0
+			iter.rh = 0
+			continue
+		}
+
+		if iter.n == iter.k {
+			iter.fh, iter.rh = NtHashRoll(iter.fh, iter.rh, iter.k, iter.seq[iter.i-1-iter.k], b)
+		} else { // shift in the base
+			iter.fh = bits.RotateLeft64(iter.fh, 1) ^ ntSeeds[b]
+			iter.rh = bits.RotateLeft64(iter.rh, -1) ^ bits.RotateLeft64(ntSeedsRC[b], iter.k-1)
+			iter.n++
+		}
+
+		if iter.n == iter.k {
+			if iter.canonical && iter.rh < iter.fh {
+				return iter.rh, iter.i - iter.k, true
+			}
+			return iter.fh, iter.i - iter.k, true
+		}
+	}
+	return 0, 0, false
+}
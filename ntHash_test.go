@@ -0,0 +1,204 @@
+// Copyright © 2018-2021 Wei Shen <shenwei356@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package kmers
+
+import (
+	"math/bits"
+	"testing"
+)
+
+func TestNtHash(t *testing.T) {
+	fh, rh, err := NtHash([]byte("A"))
+	if err != nil || fh != ntSeedA || rh != ntSeedT {
+		t.Errorf("NtHash error: %x, %x, %v", fh, rh, err)
+	}
+
+	// values computed with getFhval, getRhval, NTC64, NTF64, NTR64 and NTM64
+	// of the ntHash v1 reference implementation (nthash.hpp).
+	for _, c := range []struct {
+		kmer       string
+		fh, rh, ch uint64
+		multi      [4]uint64
+	}{
+		{
+			"ACGTCGATGCTAGCTAGCGATCGTAGCAT",
+			0xc07116001dfdf0f0, 0x3bb8b284c4b87ca3, 0x3bb8b284c4b87ca3,
+			[4]uint64{0x3bb8b284c4b87ca3, 0xaf1c8bd4b7d76bf3, 0xfbf2742c78521b90, 0x37ab26b13fb1cc6a},
+		},
+		{
+			"TTGCATGCAGTCGGACTAGCCAAT",
+			0x807e37e13a6d3490, 0xd198988e08964270, 0x807e37e13a6d3490,
+			[4]uint64{0x807e37e13a6d3490, 0x6e6a9ad54799f881, 0xeee8d2a4182d8f18, 0x6f670a9713445fef},
+		},
+	} {
+		fh, rh, _ = NtHash([]byte(c.kmer))
+		if fh != c.fh || rh != c.rh {
+			t.Errorf("NtHash error for %s: expected %x, %x, returned %x, %x", c.kmer, c.fh, c.rh, fh, rh)
+		}
+		iter, _ := NewNtHashIterator([]byte(c.kmer), len(c.kmer), true)
+		if ch, _, _ := iter.Next(); ch != c.ch {
+			t.Errorf("NtHashIterator error for %s: expected canonical %x, returned %x", c.kmer, c.ch, ch)
+		}
+		hashes := make([]uint64, 4)
+		NtMultiHash(c.ch, len(c.kmer), hashes)
+		if [4]uint64{hashes[0], hashes[1], hashes[2], hashes[3]} != c.multi {
+			t.Errorf("NtMultiHash error for %s: expected %x, returned %x", c.kmer, c.multi, hashes)
+		}
+	}
+
+	// rolling hashes
+	iter, _ := NewNtHashIterator([]byte("GATTACAGATTACACCGGTTAACGTAGCTAGCA"), 21, false)
+	var last uint64
+	for {
+		hash, _, ok := iter.Next()
+		if !ok {
+			break
+		}
+		last = hash
+	}
+	if last != 0xd11f8b48f029c9f3 {
+		t.Errorf("NtHashIterator error: expected %x, returned %x", uint64(0xd11f8b48f029c9f3), last)
+	}
+
+	// U is treated as T
+	fh, rh, _ = NtHash([]byte("ACGTU"))
+	if fh2, rh2, _ := NtHash([]byte("ACGTT")); fh != fh2 || rh != rh2 {
+		t.Errorf("NtHash error: U should be treated as T")
+	}
+
+	if _, _, err = NtHash([]byte("ACNT")); err != ErrIllegalBase {
+		t.Errorf("NtHash should fail for non-ACGT bases")
+	}
+
+	seq := randomSeq(1000)
+	rc := revCompBytes(seq)
+	for _, k := range []int{1, 5, 21, 31, 32, 33, 63, 64, 65, 100} {
+		iter, _ := NewNtHashIterator(seq, k, false)
+		iterC, _ := NewNtHashIterator(seq, k, true)
+		var n int
+		for {
+			hash, pos, ok := iter.Next()
+			if !ok {
+				break
+			}
+			hashC, _, _ := iterC.Next()
+
+			fh, rh, _ := NtHash(seq[pos : pos+k])
+			if hash != fh {
+				t.Fatalf("NtHashIterator error: k=%d, pos=%d, expected %x, returned %x", k, pos, fh, hash)
+			}
+			if min := minUint64(fh, rh); hashC != min {
+				t.Fatalf("NtHashIterator error: k=%d, pos=%d, expected canonical %x, returned %x", k, pos, min, hashC)
+			}
+
+			// the reverse hash equals the forward hash of the reverse complement
+			fh2, rh2, _ := NtHash(rc[len(seq)-pos-k : len(seq)-pos])
+			if fh2 != rh || rh2 != fh {
+				t.Fatalf("NtHash error: k=%d, pos=%d, hashes of reverse complement mismatch", k, pos)
+			}
+			n++
+		}
+		if n != len(seq)-k+1 {
+			t.Errorf("NtHashIterator error: k=%d, %d hashes returned", k, n)
+		}
+	}
+
+	// bits should be well distributed
+	iter, _ = NewNtHashIterator(randomSeq(100000), 21, true)
+	var ones, n int
+	for {
+		hash, _, ok := iter.Next()
+		if !ok {
+			break
+		}
+		ones += bits.OnesCount64(hash)
+		n++
+	}
+	if r := float64(ones) / float64(n); r < 31 || r > 33 {
+		t.Errorf("NtHash error: %f bits set on average", r)
+	}
+}
+
+func TestNtHashIteratorIllegalBases(t *testing.T) {
+	seq := []byte("ACGTNACGTAnACG")
+	iter, _ := NewNtHashIterator(seq, 4, false)
+	var positions []int
+	for {
+		hash, pos, ok := iter.Next()
+		if !ok {
+			break
+		}
+		if fh, _, _ := NtHash(seq[pos : pos+4]); fh != hash {
+			t.Errorf("NtHashIterator error: pos=%d, expected %x, returned %x", pos, fh, hash)
+		}
+		positions = append(positions, pos)
+	}
+	if len(positions) != 3 || positions[0] != 0 || positions[1] != 5 || positions[2] != 6 {
+		t.Errorf("NtHashIterator error: positions %v", positions)
+	}
+
+	iter.Reset(seq)
+	iter.SetPolicy(Strict)
+	for {
+		if _, _, ok := iter.Next(); !ok {
+			break
+		}
+	}
+	if iter.Err() != ErrIllegalBase {
+		t.Errorf("NtHashIterator should fail in Strict policy")
+	}
+
+	iter.Reset([]byte("ACGUACGT"))
+	iter.SetPolicy(Skip)
+	if _, pos, ok := iter.Next(); !ok || pos != 4 {
+		t.Errorf("NtHashIterator should skip U in Skip policy")
+	}
+}
+
+func TestNtMultiHash(t *testing.T) {
+	k := 21
+	fh, _, _ := NtHash([]byte("ACGTACGTACGTACGTACGTA"))
+	hashes := make([]uint64, 5)
+	NtMultiHash(fh, k, hashes)
+	if hashes[0] != fh {
+		t.Errorf("NtMultiHash error: the first hash should be %x", fh)
+	}
+	seen := make(map[uint64]struct{}, len(hashes))
+	for i, h := range hashes {
+		if i > 0 {
+			t0 := fh * (uint64(i) ^ uint64(k)*ntMultiSeed)
+			if h != t0^t0>>ntMultiShift {
+				t.Errorf("NtMultiHash error: hash %d mismatch", i)
+			}
+		}
+		seen[h] = struct{}{}
+	}
+	if len(seen) != len(hashes) {
+		t.Errorf("NtMultiHash error: duplicated hashes")
+	}
+}
+
+func minUint64(a, b uint64) uint64 {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,176 @@
+// Copyright © 2018-2021 Wei Shen <shenwei356@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package kmers
+
+// NRun is a run of non-ACGT bases in [Start, End) (0-based).
+type NRun struct {
+	Start int
+	End   int
+}
+
+// PackedSeq is a nucleotide sequence stored in 2 bits per base,
+// in the same layout as LongKmer, so k-mers at any position can be retrieved in O(1) time.
+// Bases other than A, C, G, T (and U) are stored as A, with their positions
+// recorded in a side list of N runs and a bitmask, and they are restored as N.
+type PackedSeq struct {
+	seq   LongKmer
+	nRuns []NRun
+	nMask []uint64 // 1 bit per base, MSB-first, nil if there's no N runs
+}
+
+// bits2bit maps A, C, G, T in base2bits to 2-bit codes.
+var bits2bit = [9]uint8{1: 0, 2: 1, 4: 2, 8: 3}
+
+// NewPackedSeq creates a PackedSeq from a sequence.
+func NewPackedSeq(s []byte) *PackedSeq {
+	p := &PackedSeq{seq: LongKmer{make([]uint64, nWords(len(s))), len(s)}}
+
+	var bits uint8
+	inRun := false
+	for i, b := range s {
+		bits = base2bits[b]
+		switch bits {
+		case 1, 2, 4, 8:
+			p.seq.Words[i>>5] |= uint64(bits2bit[bits]) << (uint(31-i&31) << 1)
+			inRun = false
+		default:
+			p.setN(i)
+			if inRun {
+				p.nRuns[len(p.nRuns)-1].End++
+			} else {
+				p.nRuns = append(p.nRuns, NRun{i, i + 1})
+				inRun = true
+			}
+		}
+	}
+	return p
+}
+
+// Len returns the length of the sequence.
+func (p *PackedSeq) Len() int {
+	return p.seq.K
+}
+
+// NRuns returns runs of non-ACGT bases.
+func (p *PackedSeq) NRuns() []NRun {
+	return p.nRuns
+}
+
+// setN marks the base in pos i as a non-ACGT base in the bitmask.
+func (p *PackedSeq) setN(i int) {
+	if p.nMask == nil {
+		p.nMask = make([]uint64, (p.seq.K+63)>>6)
+	}
+	p.nMask[i>>6] |= 1 << uint(63-i&63)
+}
+
+// hasN checks if there are non-ACGT bases in [start, end) in O(1) time,
+// where end-start should be in [1, 64].
+func (p *PackedSeq) hasN(start, end int) bool {
+	if p.nMask == nil {
+		return false
+	}
+	j, off := start>>6, uint(start&63)
+	w := p.nMask[j] << off
+	if off > 0 && j+1 < len(p.nMask) {
+		w |= p.nMask[j+1] >> (64 - off)
+	}
+	return w>>uint(64-(end-start)) != 0
+}
+
+// BaseAt returns the base in pos i (0-based).
+func (p *PackedSeq) BaseAt(i int) byte {
+	if i < 0 || i >= p.seq.K {
+		panic(ErrPositionOverflow)
+	}
+	if p.hasN(i, i+1) {
+		return 'N'
+	}
+	return bit2base[p.seq.BaseAt(i)]
+}
+
+// KmerAt returns the k-mer (k<=32) starting at position i (0-based).
+// ErrIllegalBase is returned if the k-mer contains non-ACGT bases.
+func (p *PackedSeq) KmerAt(i int, k int) (KmerCode, error) {
+	if k <= 0 || k > 32 {
+		return KmerCode{}, ErrKOverflow
+	}
+	if i < 0 || i+k > p.seq.K {
+		return KmerCode{}, ErrPositionOverflow
+	}
+	if p.hasN(i, i+k) {
+		return KmerCode{}, ErrIllegalBase
+	}
+	return p.MustKmerAt(i, k), nil
+}
+
+// MustKmerAt is similar to KmerAt, but does not check k, i and non-ACGT bases,
+// which are returned as A.
+func (p *PackedSeq) MustKmerAt(i int, k int) KmerCode {
+	j, off := i>>5, uint(i&31)<<1
+	code := p.seq.Words[j] << off
+	if off > 0 && j+1 < len(p.seq.Words) {
+		code |= p.seq.Words[j+1] >> (64 - off)
+	}
+	return KmerCode{code >> (uint(32-k) << 1), k}
+}
+
+// Bytes returns the sequence in []byte, where non-ACGT bases are returned as N.
+func (p *PackedSeq) Bytes() []byte {
+	s := p.seq.Bytes()
+	for _, r := range p.nRuns {
+		for i := r.Start; i < r.End; i++ {
+			s[i] = 'N'
+		}
+	}
+	return s
+}
+
+// String returns the sequence in string.
+func (p *PackedSeq) String() string {
+	return string(p.Bytes())
+}
+
+// RevComp returns the reverse complement sequence.
+func (p *PackedSeq) RevComp() *PackedSeq {
+	if p.seq.K == 0 {
+		return &PackedSeq{}
+	}
+	rc := &PackedSeq{seq: p.seq.RevComp()}
+
+	// N bases are stored as A, which are complemented to T.
+	var i int
+	for _, r := range p.nRuns {
+		for i = r.Start; i < r.End; i++ {
+			j := p.seq.K - 1 - i
+			rc.seq.Words[j>>5] &^= 3 << (uint(31-j&31) << 1)
+			rc.setN(j)
+		}
+	}
+
+	if len(p.nRuns) > 0 {
+		rc.nRuns = make([]NRun, len(p.nRuns))
+		for i, r := range p.nRuns {
+			rc.nRuns[len(p.nRuns)-1-i] = NRun{p.seq.K - r.End, p.seq.K - r.Start}
+		}
+	}
+	return rc
+}
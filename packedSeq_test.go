@@ -0,0 +1,92 @@
+// Copyright © 2018-2021 Wei Shen <shenwei356@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package kmers
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestPackedSeq(t *testing.T) {
+	seq := append(randomSeq(50), []byte("NNNRacgtuACGTN")...)
+	seq = append(seq, randomSeq(70)...)
+	seq = append(seq, 'N')
+	expected := bytes.ToUpper(seq)
+	expected = bytes.Replace(expected, []byte("R"), []byte("N"), -1)
+	expected = bytes.Replace(expected, []byte("U"), []byte("T"), -1)
+
+	p := NewPackedSeq(seq)
+	if p.Len() != len(seq) {
+		t.Errorf("Len error: expected %d, returned %d", len(seq), p.Len())
+	}
+	if !bytes.Equal(p.Bytes(), expected) {
+		t.Errorf("Bytes error: expected %s, returned %s", expected, p.Bytes())
+	}
+	if len(p.NRuns()) != 3 {
+		t.Errorf("NRuns error: %v", p.NRuns())
+	}
+
+	rc := p.RevComp()
+	if !bytes.Equal(rc.Bytes(), revCompBytesN(expected)) {
+		t.Errorf("RevComp error: expected %s, returned %s", revCompBytesN(expected), rc.Bytes())
+	}
+	if !bytes.Equal(rc.RevComp().Bytes(), expected) {
+		t.Errorf("RevComp().RevComp() error: %s", rc.RevComp())
+	}
+
+	for _, k := range []int{1, 5, 31, 32} {
+		for i := 0; i+k <= len(seq); i++ {
+			kcode, err := p.KmerAt(i, k)
+			code, err0 := Encode(expected[i : i+k])
+			if bytes.IndexByte(expected[i:i+k], 'N') >= 0 {
+				if err != ErrIllegalBase {
+					t.Errorf("KmerAt should fail for %s", expected[i:i+k])
+				}
+				continue
+			}
+			if err != nil || err0 != nil || kcode.Code != code || kcode.K != k {
+				t.Errorf("KmerAt error at %d: expected %s, returned %s", i, expected[i:i+k], kcode)
+			}
+		}
+	}
+	rcExpected := revCompBytesN(expected)
+	for i := 0; i+32 <= len(seq); i++ {
+		_, err := rc.KmerAt(i, 32)
+		if hasN := bytes.IndexByte(rcExpected[i:i+32], 'N') >= 0; hasN != (err == ErrIllegalBase) {
+			t.Errorf("KmerAt error for the reverse complement at %d: %v", i, err)
+		}
+	}
+	if _, err := p.KmerAt(len(seq)-4, 5); err != ErrPositionOverflow {
+		t.Errorf("KmerAt should fail for positions out of range")
+	}
+}
+
+func revCompBytesN(s []byte) []byte {
+	rc := make([]byte, len(s))
+	for i, b := range s {
+		if b == 'N' {
+			rc[len(s)-1-i] = 'N'
+		} else {
+			rc[len(s)-1-i] = bit2base[base2bit[b]^3]
+		}
+	}
+	return rc
+}
@@ -0,0 +1,216 @@
+// Copyright © 2018-2021 Wei Shen <shenwei356@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package kmers
+
+import (
+	"bytes"
+	"errors"
+)
+
+// ErrIllegalAminoAcid means that symbols beyond amino acids are detected.
+var ErrIllegalAminoAcid = errors.New("kmers: illegal amino acid")
+
+// ErrKOverflowProtein means K > 12 for protein k-mers.
+var ErrKOverflowProtein = errors.New("kmers: protein k-mer size (1-12) overflow")
+
+// aminoAcids are amino acid symbols in the order of their codes.
+var aminoAcids = []byte("ACDEFGHIKLMNPQRSTVWYBJOUXZ*")
+
+// aa2bit maps amino acid symbols to 5-bit codes, 31 for illegal ones.
+var aa2bit [256]uint64
+
+func init() {
+	for i := range aa2bit {
+		aa2bit[i] = 31
+	}
+	for i, aa := range aminoAcids {
+		aa2bit[aa] = uint64(i)
+		if aa >= 'A' && aa <= 'Z' {
+			aa2bit[aa+32] = uint64(i) // lower case
+		}
+	}
+}
+
+// EncodeProtein converts a protein k-mer (k<=12) to bits, 5 bits for each residue.
+//
+// Codes are assigned in the order below, i.e., A is 0, C is 1, and * is 26.
+//
+//	ACDEFGHIKLMNPQRSTVWY  20 standard amino acids
+//	BJOUXZ                ambiguous and non-standard ones
+//	*                     stop codon
+//
+// Lower-case symbols are also accepted.
+func EncodeProtein(kmer []byte) (code uint64, err error) {
+	if len(kmer) == 0 || len(kmer) > 12 {
+		return 0, ErrKOverflowProtein
+	}
+
+	var v uint64
+	for _, b := range kmer {
+		code <<= 5
+		v = aa2bit[b]
+		if v == 31 {
+			return code, ErrIllegalAminoAcid
+		}
+		code |= v
+	}
+	return code, nil
+}
+
+// MustEncodeProteinFromFormerKmer encodes from former the protein k-mer,
+// assuming the k-mer and leftKmer are both OK.
+func MustEncodeProteinFromFormerKmer(kmer []byte, leftKmer []byte, leftCode uint64) (uint64, error) {
+	v := aa2bit[kmer[len(kmer)-1]]
+	if v == 31 {
+		return leftCode, ErrIllegalAminoAcid
+	}
+	// retrieve lower (k-1)*5 bits and << 5, and then add v
+	return (leftCode&((1<<(uint(len(kmer)-1)*5))-1))<<5 | v, nil
+}
+
+// EncodeProteinFromFormerKmer encodes from the former protein k-mer.
+func EncodeProteinFromFormerKmer(kmer []byte, leftKmer []byte, leftCode uint64) (uint64, error) {
+	if len(kmer) == 0 || len(kmer) > 12 {
+		return 0, ErrKOverflowProtein
+	}
+	if len(kmer) != len(leftKmer) {
+		return 0, ErrKMismatch
+	}
+	if !bytes.Equal(kmer[0:len(kmer)-1], leftKmer[1:]) {
+		return 0, ErrNotConsecutiveKmers
+	}
+	return MustEncodeProteinFromFormerKmer(kmer, leftKmer, leftCode)
+}
+
+// MustEncodeProteinFromLatterKmer encodes from the latter protein k-mer,
+// assuming the k-mer and rightKmer are both OK.
+func MustEncodeProteinFromLatterKmer(kmer []byte, rightKmer []byte, rightCode uint64) (uint64, error) {
+	v := aa2bit[kmer[0]]
+	if v == 31 {
+		return rightCode, ErrIllegalAminoAcid
+	}
+	return v<<(uint(len(kmer)-1)*5) | rightCode>>5, nil
+}
+
+// EncodeProteinFromLatterKmer encodes from the latter protein k-mer.
+func EncodeProteinFromLatterKmer(kmer []byte, rightKmer []byte, rightCode uint64) (uint64, error) {
+	if len(kmer) == 0 || len(kmer) > 12 {
+		return 0, ErrKOverflowProtein
+	}
+	if len(kmer) != len(rightKmer) {
+		return 0, ErrKMismatch
+	}
+	if !bytes.Equal(rightKmer[0:len(kmer)-1], kmer[1:len(rightKmer)]) {
+		return 0, ErrNotConsecutiveKmers
+	}
+	return MustEncodeProteinFromLatterKmer(kmer, rightKmer, rightCode)
+}
+
+// DecodeProtein converts the code to original protein k-mer.
+func DecodeProtein(code uint64, k int) []byte {
+	if k <= 0 || k > 12 {
+		panic(ErrKOverflowProtein)
+	}
+	if code >= 1<<(uint(k)*5) {
+		panic(ErrCodeOverflow)
+	}
+	kmer := make([]byte, k)
+	var v uint64
+	for i := 0; i < k; i++ {
+		v = code & 31
+		if v >= uint64(len(aminoAcids)) {
+			panic(ErrCodeOverflow)
+		}
+		kmer[k-1-i] = aminoAcids[v]
+		code >>= 5
+	}
+	return kmer
+}
+
+// MustDecodeProtein is similar to DecodeProtein, but does not check k and code.
+func MustDecodeProtein(code uint64, k int) []byte {
+	kmer := make([]byte, k)
+	for i := 0; i < k; i++ {
+		kmer[k-1-i] = aminoAcids[code&31]
+		code >>= 5
+	}
+	return kmer
+}
+
+// ProteinPrefix returns the first n residues. n needs to be > 0.
+// The length of the prefix is n.
+func ProteinPrefix(code uint64, k int, n int) uint64 {
+	if n < 1 || n > k {
+		panic(ErrLengthOverflow)
+	}
+	return code >> (uint(k-n) * 5)
+}
+
+// MustProteinPrefix returns the first n residues. n needs to be > 0.
+// The length of the prefix is n.
+func MustProteinPrefix(code uint64, k int, n int) uint64 {
+	return code >> (uint(k-n) * 5)
+}
+
+// ProteinSuffix returns the suffix starting from position i (0-based).
+// The length of the suffix is k - i.
+func ProteinSuffix(code uint64, k int, i int) uint64 {
+	if i < 0 || i >= k {
+		panic(ErrPositionOverflow)
+	}
+	return code & (1<<(uint(k-i)*5) - 1)
+}
+
+// MustProteinSuffix returns the suffix starting from position i (0-based).
+// The length of the suffix is k - i.
+func MustProteinSuffix(code uint64, k int, i int) uint64 {
+	return code & (1<<(uint(k-i)*5) - 1)
+}
+
+// ProteinKmerCode is a struct representing a protein k-mer in 64-bits.
+type ProteinKmerCode struct {
+	Code uint64
+	K    int
+}
+
+// NewProteinKmerCode returns a new ProteinKmerCode struct from byte slice.
+func NewProteinKmerCode(kmer []byte) (ProteinKmerCode, error) {
+	code, err := EncodeProtein(kmer)
+	if err != nil {
+		return ProteinKmerCode{}, err
+	}
+	return ProteinKmerCode{code, len(kmer)}, err
+}
+
+// Equal checks wether two ProteinKmerCodes are the same.
+func (kcode ProteinKmerCode) Equal(kcode2 ProteinKmerCode) bool {
+	return kcode.K == kcode2.K && kcode.Code == kcode2.Code
+}
+
+// Bytes returns k-mer in []byte.
+func (kcode ProteinKmerCode) Bytes() []byte {
+	return DecodeProtein(kcode.Code, kcode.K)
+}
+
+// String returns k-mer in string
+func (kcode ProteinKmerCode) String() string {
+	return string(DecodeProtein(kcode.Code, kcode.K))
+}
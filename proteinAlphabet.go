@@ -0,0 +1,211 @@
+// Copyright © 2018-2021 Wei Shen <shenwei356@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package kmers
+
+import (
+	"bytes"
+	"errors"
+	"math/bits"
+)
+
+// ErrInvalidAlphabet means the definition of a protein alphabet is invalid.
+var ErrInvalidAlphabet = errors.New("kmers: invalid protein alphabet")
+
+// ErrKOverflowAlphabet means K > the maximum k-mer size of a protein alphabet.
+var ErrKOverflowAlphabet = errors.New("kmers: k-mer size overflow for the alphabet")
+
+// aaIllegal marks residues not in a protein alphabet.
+const aaIllegal = 1<<64 - 1
+
+// ProteinAlphabet is a reduced amino acid alphabet, which maps residues into
+// fewer symbols, so that each residue takes fewer bits,
+// and longer protein k-mers can be encoded in a uint64.
+// Symbols are numbered in the order of groups,
+// and each symbol is decoded to the first residue of its group.
+type ProteinAlphabet struct {
+	name    string
+	groups  []string
+	symbols []byte
+	bits    uint
+	maxK    int
+	aa2bit  [256]uint64
+}
+
+// Murphy10 is the 10-letter alphabet in Murphy et al. (2000), Protein Eng.
+var Murphy10 = mustNewProteinAlphabet("Murphy-10",
+	[]string{"LVIM", "C", "A", "G", "ST", "P", "FYW", "EDNQ", "KR", "H"})
+
+// Dayhoff6 is the 6-letter alphabet of Dayhoff groups.
+var Dayhoff6 = mustNewProteinAlphabet("Dayhoff-6",
+	[]string{"AGPST", "C", "DENQ", "HKR", "ILMV", "FWY"})
+
+// SEB14 is the 14-letter alphabet SE-B(14) in Peterson et al. (2009), Bioinformatics.
+var SEB14 = mustNewProteinAlphabet("SE-B(14)",
+	[]string{"A", "C", "D", "EQ", "FY", "G", "H", "IV", "KR", "LM", "N", "P", "ST", "W"})
+
+func mustNewProteinAlphabet(name string, groups []string) *ProteinAlphabet {
+	alphabet, err := NewProteinAlphabet(name, groups)
+	if err != nil {
+		panic(err)
+	}
+	return alphabet
+}
+
+// NewProteinAlphabet creates a protein alphabet from groups of residues,
+// residues in a group are mapped to the same symbol.
+// Residues are case-insensitive, and every residue can only belong to one group.
+func NewProteinAlphabet(name string, groups []string) (*ProteinAlphabet, error) {
+	if len(groups) == 0 {
+		return nil, ErrInvalidAlphabet
+	}
+
+	alphabet := &ProteinAlphabet{
+		name:    name,
+		groups:  groups,
+		symbols: make([]byte, len(groups)),
+	}
+	for i := range alphabet.aa2bit {
+		alphabet.aa2bit[i] = aaIllegal
+	}
+
+	var b byte
+	for i, group := range groups {
+		if len(group) == 0 {
+			return nil, ErrInvalidAlphabet
+		}
+		alphabet.symbols[i] = group[0]
+		for j := 0; j < len(group); j++ {
+			b = group[j]
+			if b >= 'a' && b <= 'z' {
+				b -= 32
+			}
+			if alphabet.aa2bit[b] != aaIllegal {
+				return nil, ErrInvalidAlphabet
+			}
+			alphabet.aa2bit[b] = uint64(i)
+			if b >= 'A' && b <= 'Z' {
+				alphabet.aa2bit[b+32] = uint64(i) // lower case
+			}
+		}
+	}
+
+	alphabet.bits = uint(bits.Len(uint(len(groups) - 1)))
+	if alphabet.bits == 0 {
+		alphabet.bits = 1
+	}
+	alphabet.maxK = 64 / int(alphabet.bits)
+	return alphabet, nil
+}
+
+// Name returns the name of the alphabet.
+func (alphabet *ProteinAlphabet) Name() string {
+	return alphabet.name
+}
+
+// Size returns the number of symbols.
+func (alphabet *ProteinAlphabet) Size() int {
+	return len(alphabet.symbols)
+}
+
+// Bits returns the number of bits for each residue.
+func (alphabet *ProteinAlphabet) Bits() int {
+	return int(alphabet.bits)
+}
+
+// MaxK returns the maximum k-mer size that fits in a uint64.
+func (alphabet *ProteinAlphabet) MaxK() int {
+	return alphabet.maxK
+}
+
+// String returns the groups of the alphabet, e.g., "Dayhoff-6: AGPST,C,DENQ,HKR,ILMV,FWY".
+func (alphabet *ProteinAlphabet) String() string {
+	var buf bytes.Buffer
+	buf.WriteString(alphabet.name)
+	buf.WriteString(": ")
+	for i, group := range alphabet.groups {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		buf.WriteString(group)
+	}
+	return buf.String()
+}
+
+// Encode converts a protein k-mer to bits in the alphabet.
+func (alphabet *ProteinAlphabet) Encode(kmer []byte) (code uint64, err error) {
+	if len(kmer) == 0 || len(kmer) > alphabet.maxK {
+		return 0, ErrKOverflowAlphabet
+	}
+
+	var v uint64
+	for _, b := range kmer {
+		code <<= alphabet.bits
+		v = alphabet.aa2bit[b]
+		if v == aaIllegal {
+			return code, ErrIllegalAminoAcid
+		}
+		code |= v
+	}
+	return code, nil
+}
+
+// MustEncodeFromFormerKmer encodes from former the protein k-mer,
+// assuming the k-mer and leftKmer are both OK.
+func (alphabet *ProteinAlphabet) MustEncodeFromFormerKmer(kmer []byte, leftKmer []byte, leftCode uint64) (uint64, error) {
+	v := alphabet.aa2bit[kmer[len(kmer)-1]]
+	if v == aaIllegal {
+		return leftCode, ErrIllegalAminoAcid
+	}
+	return (leftCode&((1<<(uint(len(kmer)-1)*alphabet.bits))-1))<<alphabet.bits | v, nil
+}
+
+// MustEncodeFromLatterKmer encodes from the latter protein k-mer,
+// assuming the k-mer and rightKmer are both OK.
+func (alphabet *ProteinAlphabet) MustEncodeFromLatterKmer(kmer []byte, rightKmer []byte, rightCode uint64) (uint64, error) {
+	v := alphabet.aa2bit[kmer[0]]
+	if v == aaIllegal {
+		return rightCode, ErrIllegalAminoAcid
+	}
+	return v<<(uint(len(kmer)-1)*alphabet.bits) | rightCode>>alphabet.bits, nil
+}
+
+// Decode converts the code to a protein k-mer,
+// where each symbol is represented by the first residue of its group.
+func (alphabet *ProteinAlphabet) Decode(code uint64, k int) []byte {
+	if k <= 0 || k > alphabet.maxK {
+		panic(ErrKOverflowAlphabet)
+	}
+	if code>>(uint(k)*alphabet.bits) != 0 {
+		panic(ErrCodeOverflow)
+	}
+	kmer := make([]byte, k)
+	mask := uint64(1)<<alphabet.bits - 1
+	var v uint64
+	for i := 0; i < k; i++ {
+		v = code & mask
+		if v >= uint64(len(alphabet.symbols)) {
+			panic(ErrCodeOverflow)
+		}
+		kmer[k-1-i] = alphabet.symbols[v]
+		code >>= alphabet.bits
+	}
+	return kmer
+}
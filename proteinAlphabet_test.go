@@ -0,0 +1,88 @@
+// Copyright © 2018-2021 Wei Shen <shenwei356@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package kmers
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestProteinAlphabet(t *testing.T) {
+	for _, alphabet := range []*ProteinAlphabet{Murphy10, Dayhoff6, SEB14} {
+		if alphabet.Bits()*alphabet.MaxK() > 64 || alphabet.MaxK() < 12 {
+			t.Errorf("%s: wrong bits %d or max k %d", alphabet, alphabet.Bits(), alphabet.MaxK())
+		}
+
+		k := alphabet.MaxK()
+		seq := bytes.Repeat(benchProtein[:len(benchProtein)-1], 2) // no stop codon
+		var pCode uint64
+		for i := 0; i+k <= len(seq); i++ {
+			kmer := seq[i : i+k]
+			code, err := alphabet.Encode(kmer)
+			if err != nil {
+				t.Errorf("%s: Encode error: %s", alphabet.Name(), kmer)
+				continue
+			}
+
+			// decoded k-mer should be encoded to the same code
+			code2, _ := alphabet.Encode(alphabet.Decode(code, k))
+			if code2 != code {
+				t.Errorf("%s: Decode error: %s", alphabet.Name(), kmer)
+			}
+
+			if i > 0 {
+				code2, _ = alphabet.MustEncodeFromFormerKmer(kmer, seq[i-1:i-1+k], pCode)
+				if code2 != code {
+					t.Errorf("%s: MustEncodeFromFormerKmer error: %s", alphabet.Name(), kmer)
+				}
+				code2, _ = alphabet.MustEncodeFromLatterKmer(seq[i-1:i-1+k], kmer, code)
+				if code2 != pCode {
+					t.Errorf("%s: MustEncodeFromLatterKmer error: %s", alphabet.Name(), seq[i-1:i-1+k])
+				}
+			}
+			pCode = code
+		}
+	}
+
+	// equivalent residues
+	c1, _ := Dayhoff6.Encode([]byte("AILW"))
+	c2, _ := Dayhoff6.Encode([]byte("gvmy"))
+	if c1 != c2 {
+		t.Errorf("Dayhoff6: AILW and GVMY should have the same code")
+	}
+	if s := string(Dayhoff6.Decode(c1, 4)); s != "AIIF" {
+		t.Errorf("Dayhoff6: Decode error: expected %s, returned %s", "AIIF", s)
+	}
+	if _, err := Dayhoff6.Encode([]byte("AX")); err != ErrIllegalAminoAcid {
+		t.Errorf("Dayhoff6: Encode should fail for illegal amino acids")
+	}
+
+	// custom alphabet
+	alphabet, err := NewProteinAlphabet("HP", []string{"AVLIMFWC", "GSTYNQDEKRHP"})
+	if err != nil {
+		t.Errorf("NewProteinAlphabet error: %s", err)
+	} else if alphabet.Bits() != 1 || alphabet.MaxK() != 64 {
+		t.Errorf("NewProteinAlphabet error: wrong bits %d or max k %d", alphabet.Bits(), alphabet.MaxK())
+	}
+	if _, err = NewProteinAlphabet("bad", []string{"AC", "Cd"}); err != ErrInvalidAlphabet {
+		t.Errorf("NewProteinAlphabet should fail for duplicated residues")
+	}
+}
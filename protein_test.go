@@ -0,0 +1,93 @@
+// Copyright © 2018-2021 Wei Shen <shenwei356@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package kmers
+
+import (
+	"bytes"
+	"testing"
+)
+
+var benchProtein = []byte("MKVLAAGIVGLLLAQPAMAQWYDERSTHCNF*")
+
+func TestEncodeDecodeProtein(t *testing.T) {
+	for k := 1; k <= 12; k++ {
+		for i := 0; i+k <= len(benchProtein); i++ {
+			kmer := benchProtein[i : i+k]
+			kcode, err := NewProteinKmerCode(bytes.ToLower(kmer))
+			if err != nil {
+				t.Errorf("EncodeProtein error: %s", kmer)
+			}
+			if !bytes.Equal(kmer, kcode.Bytes()) {
+				t.Errorf("DecodeProtein error: %s != %s ", kmer, kcode.Bytes())
+			}
+		}
+	}
+
+	if _, err := EncodeProtein([]byte("MKVLAAGIVGLLL")); err != ErrKOverflowProtein {
+		t.Errorf("EncodeProtein should fail for k=13")
+	}
+	if _, err := EncodeProtein([]byte("MKV1")); err != ErrIllegalAminoAcid {
+		t.Errorf("EncodeProtein should fail for illegal amino acids")
+	}
+}
+
+func TestEncodeProteinFromFormerAndLatterKmer(t *testing.T) {
+	k := 12
+	var pCode uint64
+	for i := 0; i+k <= len(benchProtein); i++ {
+		kmer := benchProtein[i : i+k]
+		code0, _ := EncodeProtein(kmer)
+		if i > 0 {
+			code, err := EncodeProteinFromFormerKmer(kmer, benchProtein[i-1:i-1+k], pCode)
+			if err != nil || code != code0 {
+				t.Errorf("EncodeProteinFromFormerKmer error for %s", kmer)
+			}
+		}
+		pCode = code0
+	}
+	for i := len(benchProtein) - k; i >= 0; i-- {
+		kmer := benchProtein[i : i+k]
+		code0, _ := EncodeProtein(kmer)
+		if i < len(benchProtein)-k {
+			code, err := EncodeProteinFromLatterKmer(kmer, benchProtein[i+1:i+1+k], pCode)
+			if err != nil || code != code0 {
+				t.Errorf("EncodeProteinFromLatterKmer error for %s", kmer)
+			}
+		}
+		pCode = code0
+	}
+}
+
+func TestProteinSubstringOps(t *testing.T) {
+	kmer := benchProtein[:12]
+	code, _ := EncodeProtein(kmer)
+	k := len(kmer)
+	for i := 1; i <= k; i++ {
+		if p := MustDecodeProtein(ProteinPrefix(code, k, i), i); !bytes.Equal(p, kmer[:i]) {
+			t.Errorf("ProteinPrefix error: %d, expected %s, returned %s", i, kmer[:i], p)
+		}
+	}
+	for i := 0; i < k; i++ {
+		if s := MustDecodeProtein(ProteinSuffix(code, k, i), k-i); !bytes.Equal(s, kmer[i:]) {
+			t.Errorf("ProteinSuffix error: %d, expected %s, returned %s", i, kmer[i:], s)
+		}
+	}
+}
@@ -0,0 +1,204 @@
+// Copyright © 2018-2021 Wei Shen <shenwei356@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package kmers
+
+import (
+	"errors"
+)
+
+// ErrInvalidSpacedSeed means the mask of a spaced seed is invalid.
+var ErrInvalidSpacedSeed = errors.New("kmers: invalid spaced seed, only 0 and 1 are allowed, and both ends should be 1")
+
+// SpacedSeed is a spaced seed (gapped k-mer) with a mask like 1101101101,
+// where only bases at care positions (1) are encoded.
+// The span, i.e., the length of the mask, should be <=32,
+// so the code of the whole window is still a uint64,
+// and the spaced code is extracted from it.
+type SpacedSeed struct {
+	mask      string
+	span      int
+	weight    int
+	symmetric bool
+
+	segments []seedSegment
+}
+
+// seedSegment is a run of care positions.
+type seedSegment struct {
+	shift    uint   // right shift of the window code
+	mask     uint64 // mask of bases in the run
+	outShift uint   // left shift in the spaced code
+}
+
+// NewSpacedSeed creates a SpacedSeed from a mask string.
+func NewSpacedSeed(mask string) (*SpacedSeed, error) {
+	span := len(mask)
+	if span == 0 || span > 32 {
+		return nil, ErrKOverflow
+	}
+	if mask[0] != '1' || mask[span-1] != '1' {
+		return nil, ErrInvalidSpacedSeed
+	}
+
+	seed := &SpacedSeed{mask: mask, span: span, symmetric: true}
+	for i := 0; i < span; i++ {
+		switch mask[i] {
+		case '1':
+			seed.weight++
+		case '0':
+		default:
+			return nil, ErrInvalidSpacedSeed
+		}
+		if mask[i] != mask[span-1-i] {
+			seed.symmetric = false
+		}
+	}
+
+	var start, n int
+	for i := 0; i <= span; i++ {
+		if i < span && mask[i] == '1' {
+			continue
+		}
+		if i > start { // a run of care positions in [start, i)
+			n += i - start
+			seed.segments = append(seed.segments, seedSegment{
+				shift:    uint(span-i) << 1,
+				mask:     (1 << uint((i-start)<<1)) - 1,
+				outShift: uint(seed.weight-n) << 1,
+			})
+		}
+		start = i + 1
+	}
+	return seed, nil
+}
+
+// String returns the mask.
+func (seed *SpacedSeed) String() string {
+	return seed.mask
+}
+
+// Span returns the length of the mask.
+func (seed *SpacedSeed) Span() int {
+	return seed.span
+}
+
+// Weight returns the number of care positions,
+// i.e., the number of bases in a spaced code.
+func (seed *SpacedSeed) Weight() int {
+	return seed.weight
+}
+
+// Symmetric tells whether the mask is palindromic.
+func (seed *SpacedSeed) Symmetric() bool {
+	return seed.symmetric
+}
+
+// Encode encodes bases at care positions of a k-mer with the length of span.
+// Bases at don't-care positions should also be legal.
+func (seed *SpacedSeed) Encode(kmer []byte) (uint64, error) {
+	if len(kmer) != seed.span {
+		return 0, ErrKMismatch
+	}
+	code, err := Encode(kmer)
+	if err != nil {
+		return 0, err
+	}
+	return seed.Extract(code), nil
+}
+
+// Extract returns the spaced code from the code of the whole window.
+func (seed *SpacedSeed) Extract(code uint64) (c uint64) {
+	for _, s := range seed.segments {
+		c |= (code >> s.shift & s.mask) << s.outShift
+	}
+	return c
+}
+
+// RevComp returns the spaced code of the reverse complement of the window,
+// with the mask applied in the same direction.
+// For symmetric seeds, it equals to RevComp(seed.Extract(code), seed.Weight()).
+func (seed *SpacedSeed) RevComp(code uint64) uint64 {
+	return seed.Extract(MustRevComp(code, seed.span))
+}
+
+// Canonical returns the smaller one of spaced codes of the window
+// and its reverse complement, so a window and its reverse complement
+// on the other strand have the same canonical code.
+func (seed *SpacedSeed) Canonical(code uint64) uint64 {
+	c := seed.Extract(code)
+	rc := seed.Extract(MustRevComp(code, seed.span))
+	if rc < c {
+		return rc
+	}
+	return c
+}
+
+// SpacedSeedIterator iterates spaced codes of all windows of a sequence,
+// with the window code updated in the way of CanonicalKmerIterator.
+// Windows containing illegal bases are skipped.
+type SpacedSeedIterator struct {
+	seed      *SpacedSeed
+	canonical bool
+	iter      *CanonicalKmerIterator
+}
+
+// NewSpacedSeedIterator returns a SpacedSeedIterator for the sequence.
+// Canonical spaced codes are returned if canonical is true.
+func NewSpacedSeedIterator(seq []byte, seed *SpacedSeed, canonical bool) (*SpacedSeedIterator, error) {
+	iter, err := NewCanonicalKmerIterator(seq, seed.span)
+	if err != nil {
+		return nil, err
+	}
+	return &SpacedSeedIterator{seed: seed, canonical: canonical, iter: iter}, nil
+}
+
+// SetPolicy sets the policy for handling degenerate bases, see KmerIterator.
+func (iter *SpacedSeedIterator) SetPolicy(policy EncodePolicy) {
+	iter.iter.SetPolicy(policy)
+}
+
+// Reset resets the iterator with a new sequence, so it can be reused.
+func (iter *SpacedSeedIterator) Reset(seq []byte) {
+	iter.iter.Reset(seq)
+}
+
+// Err returns the error that stopped the iteration.
+func (iter *SpacedSeedIterator) Err() error {
+	return iter.iter.Err()
+}
+
+// Next returns the spaced code of the next window and its position (0-based).
+// ok is false when there's no more windows or an error occurred, see Err().
+func (iter *SpacedSeedIterator) Next() (code uint64, pos int, ok bool) {
+	var rcCode uint64
+	code, rcCode, pos, ok = iter.iter.next()
+	if !ok {
+		return 0, 0, false
+	}
+	code = iter.seed.Extract(code)
+	if iter.canonical {
+		rcCode = iter.seed.Extract(rcCode)
+		if rcCode < code {
+			return rcCode, pos, true
+		}
+	}
+	return code, pos, true
+}
@@ -0,0 +1,92 @@
+// Copyright © 2018-2021 Wei Shen <shenwei356@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package kmers
+
+import (
+	"testing"
+)
+
+// spacedBytes returns bases at care positions.
+func spacedBytes(mask string, kmer []byte) []byte {
+	s := make([]byte, 0, len(kmer))
+	for i, b := range kmer {
+		if mask[i] == '1' {
+			s = append(s, b)
+		}
+	}
+	return s
+}
+
+func TestSpacedSeed(t *testing.T) {
+	seq := []byte("ACGTNacgtX-CCGTAAGT-ACGGATTTAAACCCGGGATCGAATGCACTGACCTGCAATG")
+
+	for _, mask := range []string{"1", "11", "101", "1101101101", "110111", "11011000000000000000000000000011", "11111111111111111111111111111111"} {
+		seed, err := NewSpacedSeed(mask)
+		if err != nil {
+			t.Errorf("NewSpacedSeed error: %s", err)
+			continue
+		}
+		span := seed.Span()
+
+		for _, canonical := range []bool{false, true} {
+			iter, _ := NewSpacedSeedIterator(seq, seed, canonical)
+			iter0, _ := NewKmerIterator(seq, span)
+			for {
+				code, pos, ok := iter.Next()
+				_, pos0, ok0 := iter0.Next()
+				if ok != ok0 {
+					t.Errorf("%s: unexpected end of iteration", mask)
+					break
+				}
+				if !ok {
+					break
+				}
+				if pos != pos0 {
+					t.Errorf("%s: expected position %d, returned %d", mask, pos0, pos)
+				}
+
+				kmer := seq[pos : pos+span]
+				c, _ := Encode(spacedBytes(mask, kmer))
+				rc, _ := Encode(spacedBytes(mask, revCompBytes(kmer)))
+				if canonical && rc < c {
+					c = rc
+				}
+				if code != c {
+					t.Errorf("%s: expected %s, returned %s", mask, Decode(c, seed.Weight()), Decode(code, seed.Weight()))
+				}
+
+				c, _ = seed.Encode(kmer)
+				if seed.Symmetric() && seed.RevComp(mustEncode(kmer)) != RevComp(c, seed.Weight()) {
+					t.Errorf("%s: RevComp error for %s", mask, kmer)
+				}
+				if canonical && seed.Canonical(mustEncode(kmer)) != code {
+					t.Errorf("%s: Canonical error for %s", mask, kmer)
+				}
+			}
+		}
+	}
+
+	for _, mask := range []string{"", "0110", "1121", "111111111111111111111111111111111"} {
+		if _, err := NewSpacedSeed(mask); err == nil {
+			t.Errorf("NewSpacedSeed should fail for %s", mask)
+		}
+	}
+}
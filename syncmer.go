@@ -0,0 +1,259 @@
+// Copyright © 2018-2021 Wei Shen <shenwei356@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package kmers
+
+import (
+	"errors"
+)
+
+// ErrInvalidSyncmerParameters means s or t of syncmers is out of range.
+var ErrInvalidSyncmerParameters = errors.New("kmers: invalid syncmer parameters, 1<=s<=k and 0<=t<=k-s needed")
+
+// SyncmerType is the type of syncmers.
+type SyncmerType int
+
+const (
+	// ClosedSyncmer selects k-mers whose smallest s-mer is at the start or the end.
+	ClosedSyncmer SyncmerType = iota
+	// OpenSyncmer selects k-mers whose smallest s-mer is at the offset t.
+	OpenSyncmer
+)
+
+// SyncmerIterator iterates closed or open syncmers of a sequence (Edgar, 2021, PeerJ).
+// s-mers are ranked with HashOrder, and the smallest s-mer of each k-mer is
+// computed incrementally from the rolling s-mer codes with a monotone deque.
+// In canonical mode, canonical s-mers and k-mers are used. Since an offset t on
+// one strand is k-s-t on the other strand, open syncmers in canonical mode are
+// k-mers with the smallest s-mer at either of the two offsets. And as the leftmost
+// one of tied smallest s-mers on one strand is the rightmost one on the other strand,
+// all offsets of the smallest s-mer are checked, so the same k-mers are selected
+// from both strands.
+type SyncmerIterator struct {
+	kIter *CanonicalKmerIterator
+	sIter *CanonicalKmerIterator
+
+	typ       SyncmerType
+	k, s, t   int
+	canonical bool
+	order     OrderFunc
+
+	deque   []minimizerItem // ring buffer of s-mers
+	start   int             // index of the front
+	size    int             // size of the deque
+	n       int             // number of consecutive s-mers
+	preSPos int             // position of the previous s-mer
+
+	nKmers    int
+	nSyncmers int
+}
+
+// NewClosedSyncmerIterator returns a SyncmerIterator of closed syncmers.
+func NewClosedSyncmerIterator(seq []byte, k int, s int, canonical bool) (*SyncmerIterator, error) {
+	return newSyncmerIterator(seq, ClosedSyncmer, k, s, 0, canonical)
+}
+
+// NewOpenSyncmerIterator returns a SyncmerIterator of open syncmers, with an offset of t (0-based).
+func NewOpenSyncmerIterator(seq []byte, k int, s int, t int, canonical bool) (*SyncmerIterator, error) {
+	return newSyncmerIterator(seq, OpenSyncmer, k, s, t, canonical)
+}
+
+func newSyncmerIterator(seq []byte, typ SyncmerType, k int, s int, t int, canonical bool) (*SyncmerIterator, error) {
+	kIter, err := NewCanonicalKmerIterator(seq, k)
+	if err != nil {
+		return nil, err
+	}
+	if s < 1 || s > k || t < 0 || t > k-s {
+		return nil, ErrInvalidSyncmerParameters
+	}
+	sIter, _ := NewCanonicalKmerIterator(seq, s)
+	return &SyncmerIterator{
+		kIter:     kIter,
+		sIter:     sIter,
+		typ:       typ,
+		k:         k,
+		s:         s,
+		t:         t,
+		canonical: canonical,
+		order:     HashOrder,
+		deque:     make([]minimizerItem, k-s+1),
+		preSPos:   -2,
+	}, nil
+}
+
+// SetPolicy sets the policy for handling degenerate bases, see KmerIterator.
+func (iter *SyncmerIterator) SetPolicy(policy EncodePolicy) {
+	iter.kIter.SetPolicy(policy)
+	iter.sIter.SetPolicy(policy)
+}
+
+// Reset resets the iterator with a new sequence, so it can be reused.
+// Statistics are not reset.
+func (iter *SyncmerIterator) Reset(seq []byte) {
+	iter.kIter.Reset(seq)
+	iter.sIter.Reset(seq)
+	iter.start = 0
+	iter.size = 0
+	iter.n = 0
+	iter.preSPos = -2
+}
+
+// Err returns the error that stopped the iteration.
+func (iter *SyncmerIterator) Err() error {
+	return iter.sIter.Err()
+}
+
+// Next returns the next syncmer, with its code, position (0-based),
+// and whether it's on the reverse complement strand in canonical mode.
+// ok is false when there's no more syncmers or an error occurred, see Err().
+func (iter *SyncmerIterator) Next() (code uint64, pos int, rc bool, ok bool) {
+	w := iter.k - iter.s + 1 // number of s-mers in a k-mer
+	var sCode, sRC, rank uint64
+	var sPos, kPos, back int
+	var rcCode uint64
+	for {
+		sCode, sRC, sPos, ok = iter.sIter.next()
+		if !ok {
+			return 0, 0, false, false
+		}
+
+		if sPos != iter.preSPos+1 { // restarted after illegal bases
+			iter.size = 0
+			iter.n = 0
+		}
+		iter.preSPos = sPos
+		iter.n++
+
+		if iter.size > 0 && iter.deque[iter.start].pos <= sPos-w {
+			iter.start = (iter.start + 1) % w
+			iter.size--
+		}
+
+		if iter.canonical && sRC < sCode {
+			sCode = sRC
+		}
+		rank = iter.order(sCode)
+		for iter.size > 0 {
+			back = (iter.start + iter.size - 1) % w
+			if iter.deque[back].rank <= rank {
+				break
+			}
+			iter.size--
+		}
+		iter.deque[(iter.start+iter.size)%w] = minimizerItem{rank: rank, pos: sPos}
+		iter.size++
+
+		if iter.n < w {
+			continue
+		}
+
+		// the k-mer ending with this s-mer, both iterators skip the same illegal bases.
+		pos = sPos - w + 1
+		for {
+			code, rcCode, kPos, ok = iter.kIter.next()
+			if !ok || kPos >= pos {
+				break
+			}
+		}
+		if !ok {
+			return 0, 0, false, false
+		}
+		iter.nKmers++
+
+		if !iter.selectedKmer(pos) {
+			continue
+		}
+		iter.nSyncmers++
+
+		if iter.canonical && rcCode < code {
+			return rcCode, pos, true, true
+		}
+		return code, pos, false, true
+	}
+}
+
+// selectedKmer checks if the k-mer at pos is a syncmer. In canonical mode,
+// all s-mers with the smallest rank are checked, they are at the front of the deque.
+func (iter *SyncmerIterator) selectedKmer(pos int) bool {
+	front := iter.deque[iter.start]
+	if iter.selected(front.pos - pos) {
+		return true
+	}
+	if !iter.canonical {
+		return false
+	}
+	w := len(iter.deque)
+	var item minimizerItem
+	for i := 1; i < iter.size; i++ {
+		item = iter.deque[(iter.start+i)%w]
+		if item.rank != front.rank {
+			break
+		}
+		if iter.selected(item.pos - pos) {
+			return true
+		}
+	}
+	return false
+}
+
+// selected checks if a k-mer with the smallest s-mer at the offset is a syncmer.
+func (iter *SyncmerIterator) selected(offset int) bool {
+	switch iter.typ {
+	case ClosedSyncmer:
+		return offset == 0 || offset == iter.k-iter.s
+	default:
+		if offset == iter.t {
+			return true
+		}
+		return iter.canonical && offset == iter.k-iter.s-iter.t
+	}
+}
+
+// Stats returns the number of k-mers and syncmers visited so far.
+func (iter *SyncmerIterator) Stats() (kmers int, syncmers int) {
+	return iter.nKmers, iter.nSyncmers
+}
+
+// Density returns the fraction of k-mers selected as syncmers so far.
+func (iter *SyncmerIterator) Density() float64 {
+	if iter.nKmers == 0 {
+		return 0
+	}
+	return float64(iter.nSyncmers) / float64(iter.nKmers)
+}
+
+// ExpectedDensity returns the expected density for random sequences,
+// i.e., 2/(k-s+1) for closed syncmers, and 1/(k-s+1) for open syncmers,
+// or 2/(k-s+1) in canonical mode if t != k-s-t.
+func (iter *SyncmerIterator) ExpectedDensity() float64 {
+	w := float64(iter.k - iter.s + 1)
+	switch iter.typ {
+	case ClosedSyncmer:
+		if iter.k == iter.s {
+			return 1
+		}
+		return 2 / w
+	default:
+		if iter.canonical && iter.t != iter.k-iter.s-iter.t {
+			return 2 / w
+		}
+		return 1 / w
+	}
+}
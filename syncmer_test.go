@@ -0,0 +1,194 @@
+// Copyright © 2018-2021 Wei Shen <shenwei356@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package kmers
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSyncmerIterator(t *testing.T) {
+	seq := append(randomSeq(500), []byte("NNNNXAAAAAAAAAAAAAAAAAAAAAAAAX")...)
+	seq = append(seq, randomSeq(300)...)
+
+	for _, c := range []struct {
+		typ       SyncmerType
+		k, s, t   int
+		canonical bool
+	}{
+		{ClosedSyncmer, 15, 5, 0, false},
+		{ClosedSyncmer, 21, 11, 0, true},
+		{ClosedSyncmer, 5, 5, 0, true},
+		{ClosedSyncmer, 15, 3, 0, true},
+		{OpenSyncmer, 15, 5, 3, false},
+		{OpenSyncmer, 31, 15, 2, true},
+		{OpenSyncmer, 31, 15, 8, true},
+	} {
+		// brute force
+		var expected []kmerInfo
+		for _, run := range canonicalKmers(seq, c.k) {
+			for _, x := range run {
+				// offsets of the smallest s-mer, all tied ones in canonical mode
+				var minRank uint64
+				var offsets []int
+				for i := 0; i+c.s <= c.k; i++ {
+					sCode := mustEncode(seq[x.pos+i : x.pos+i+c.s])
+					if c.canonical {
+						sCode = Canonical(sCode, c.s)
+					}
+					rank := HashOrder(sCode)
+					if i == 0 || rank < minRank {
+						minRank, offsets = rank, []int{i}
+					} else if rank == minRank && c.canonical {
+						offsets = append(offsets, i)
+					}
+				}
+
+				var ok bool
+				for _, offset := range offsets {
+					if c.typ == ClosedSyncmer {
+						ok = offset == 0 || offset == c.k-c.s
+					} else {
+						ok = offset == c.t || (c.canonical && offset == c.k-c.s-c.t)
+					}
+					if ok {
+						break
+					}
+				}
+				if !ok {
+					continue
+				}
+				if !c.canonical {
+					x.code, x.rc = mustEncode(seq[x.pos:x.pos+c.k]), false
+				}
+				expected = append(expected, x)
+			}
+		}
+
+		var iter *SyncmerIterator
+		var err error
+		if c.typ == ClosedSyncmer {
+			iter, err = NewClosedSyncmerIterator(seq, c.k, c.s, c.canonical)
+		} else {
+			iter, err = NewOpenSyncmerIterator(seq, c.k, c.s, c.t, c.canonical)
+		}
+		if err != nil {
+			t.Fatalf("%v: NewSyncmerIterator error: %s", c, err)
+		}
+
+		var i int
+		for {
+			code, pos, rc, ok := iter.Next()
+			if !ok {
+				break
+			}
+			if i >= len(expected) {
+				t.Errorf("%v: unexpected syncmer at %d", c, pos)
+				break
+			}
+			if e := expected[i]; code != e.code || pos != e.pos || rc != e.rc {
+				t.Errorf("%v: expected %v, returned %v", c, e, kmerInfo{code, pos, rc})
+			}
+			i++
+		}
+		if i != len(expected) {
+			t.Errorf("%v: expected %d syncmers, returned %d", c, len(expected), i)
+		}
+		if _, n := iter.Stats(); n != len(expected) {
+			t.Errorf("%v: Stats error: expected %d syncmers, returned %d", c, len(expected), n)
+		}
+	}
+
+	if _, err := NewOpenSyncmerIterator(seq, 15, 5, 11, false); err != ErrInvalidSyncmerParameters {
+		t.Errorf("NewOpenSyncmerIterator should fail for t > k-s")
+	}
+}
+
+func TestSyncmerIteratorBothStrands(t *testing.T) {
+	seq := randomSeq(100000)
+	rc := revCompBytes(seq)
+
+	syncmers := func(seq []byte, typ SyncmerType, k, s, t int) map[int]uint64 {
+		var iter *SyncmerIterator
+		if typ == ClosedSyncmer {
+			iter, _ = NewClosedSyncmerIterator(seq, k, s, true)
+		} else {
+			iter, _ = NewOpenSyncmerIterator(seq, k, s, t, true)
+		}
+		m := make(map[int]uint64, 1024)
+		for {
+			code, pos, _, ok := iter.Next()
+			if !ok {
+				break
+			}
+			m[pos] = code
+		}
+		return m
+	}
+
+	for _, c := range []struct {
+		typ     SyncmerType
+		k, s, t int
+	}{
+		{ClosedSyncmer, 15, 5, 0},
+		{ClosedSyncmer, 21, 8, 0},
+		{ClosedSyncmer, 15, 2, 0},
+		{OpenSyncmer, 15, 5, 3},
+		{OpenSyncmer, 21, 3, 5},
+	} {
+		m1 := syncmers(seq, c.typ, c.k, c.s, c.t)
+		m2 := syncmers(rc, c.typ, c.k, c.s, c.t)
+		if len(m1) != len(m2) {
+			t.Errorf("%v: %d syncmers on the forward strand, %d on the reverse strand", c, len(m1), len(m2))
+		}
+		for pos, code := range m1 {
+			if code2, ok := m2[len(seq)-c.k-pos]; !ok || code2 != code {
+				t.Errorf("%v: syncmer at %d is not selected on the reverse strand", c, pos)
+				break
+			}
+		}
+	}
+}
+
+func TestSyncmerDensity(t *testing.T) {
+	seq := randomSeq(200000)
+	for _, canonical := range []bool{false, true} {
+		iter, _ := NewClosedSyncmerIterator(seq, 31, 15, canonical)
+		for {
+			if _, _, _, ok := iter.Next(); !ok {
+				break
+			}
+		}
+		if d, e := iter.Density(), iter.ExpectedDensity(); math.Abs(d-e)/e > 0.1 {
+			t.Errorf("closed syncmer density error: expected %f, returned %f", e, d)
+		}
+
+		iter, _ = NewOpenSyncmerIterator(seq, 31, 15, 8, canonical)
+		for {
+			if _, _, _, ok := iter.Next(); !ok {
+				break
+			}
+		}
+		if d, e := iter.Density(), iter.ExpectedDensity(); math.Abs(d-e)/e > 0.1 {
+			t.Errorf("open syncmer density error: expected %f, returned %f", e, d)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+// Copyright © 2018-2021 Wei Shen <shenwei356@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package kmers
+
+import (
+	"bufio"
+	"io"
+)
+
+// KmerWriter writes decoded k-mers to an io.Writer, one k-mer per line.
+// A buffer is reused for decoding, so no allocation is needed for each k-mer.
+type KmerWriter struct {
+	w   *bufio.Writer
+	k   int
+	buf []byte
+}
+
+// NewKmerWriter returns a KmerWriter for k-mers of size k.
+func NewKmerWriter(w io.Writer, k int) (*KmerWriter, error) {
+	if k <= 0 || k > 32 {
+		return nil, ErrKOverflow
+	}
+	return &KmerWriter{
+		w:   bufio.NewWriter(w),
+		k:   k,
+		buf: make([]byte, 0, k+1),
+	}, nil
+}
+
+// Write writes a k-mer.
+func (w *KmerWriter) Write(code uint64) error {
+	if code > MaxCode[w.k] {
+		return ErrCodeOverflow
+	}
+	w.buf = MustAppendDecode(w.buf[:0], code, w.k)
+	w.buf = append(w.buf, '\n')
+	_, err := w.w.Write(w.buf)
+	return err
+}
+
+// WriteCodes writes a list of k-mers.
+func (w *KmerWriter) WriteCodes(codes []uint64) error {
+	var err error
+	for _, code := range codes {
+		if err = w.Write(code); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Flush writes any buffered data to the underlying io.Writer.
+func (w *KmerWriter) Flush() error {
+	return w.w.Flush()
+}
@@ -0,0 +1,99 @@
+// Copyright © 2018-2021 Wei Shen <shenwei356@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package kmers
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+)
+
+func TestKmerWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewKmerWriter(&buf, 5)
+	if err != nil {
+		t.Fatalf("NewKmerWriter error: %s", err)
+	}
+
+	var expected bytes.Buffer
+	codes := make([]uint64, 0, len(benchMer))
+	for i := 0; i+5 <= len(benchMer); i++ {
+		code, _ := Encode(benchMer[i : i+5])
+		codes = append(codes, code)
+		expected.Write(Decode(code, 5))
+		expected.WriteByte('\n')
+	}
+	if err = w.WriteCodes(codes); err != nil {
+		t.Errorf("WriteCodes error: %s", err)
+	}
+	if err = w.Flush(); err != nil {
+		t.Errorf("Flush error: %s", err)
+	}
+	if buf.String() != expected.String() {
+		t.Errorf("KmerWriter error: expected %s, returned %s", expected.String(), buf.String())
+	}
+
+	if err = w.Write(1 << 10); err != ErrCodeOverflow {
+		t.Errorf("Write should fail for code overflow")
+	}
+}
+
+func TestAppendDecode(t *testing.T) {
+	buf := make([]byte, 0, 64)
+	for _, mer := range randomMers {
+		kcode, _ := NewKmerCode(mer)
+		buf = AppendDecode(buf[:0], kcode.Code, kcode.K)
+		if !bytes.Equal(buf, mer) {
+			t.Errorf("AppendDecode error: %s != %s ", mer, buf)
+		}
+		if kcode.String() != string(mer) {
+			t.Errorf("String error: %s != %s ", mer, kcode.String())
+		}
+		var bits bytes.Buffer
+		for _, b := range mer {
+			bits.WriteString(bit2str[base2bit[b]])
+		}
+		if kcode.BitsString() != bits.String() {
+			t.Errorf("BitsString error: %s != %s ", kcode.BitsString(), bits.String())
+		}
+	}
+
+	buf = AppendDecode([]byte("ACGT"), 0, 3)
+	if string(buf) != "ACGTAAA" {
+		t.Errorf("AppendDecode error: %s != %s ", "ACGTAAA", buf)
+	}
+}
+
+func BenchmarkAppendDecodeK32(b *testing.B) {
+	buf := make([]byte, 0, 32)
+	for i := 0; i < b.N; i++ {
+		buf = AppendDecode(buf[:0], benchCode, len(benchMer))
+	}
+	result2 = buf
+}
+
+func BenchmarkKmerWriterK32(b *testing.B) {
+	w, _ := NewKmerWriter(ioutil.Discard, len(benchMer))
+	for i := 0; i < b.N; i++ {
+		w.Write(benchCode)
+	}
+	w.Flush()
+}